@@ -0,0 +1,66 @@
+// Package cache records successful component builds, keyed by content hashes
+package cache
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Cache stores values by key, e.g. the records of successful builds by the
+// content hash of the component
+type Cache interface {
+	// Get reads the value stored under a key, if there is one
+	Get(key string) ([]byte, bool, error)
+	// Put stores a value under a key
+	Put(key string, value []byte) error
+}
+
+// Open opens a cache at a location, which is either a local directory, the URL
+// of an HTTP cache server (http:// or https://) or an S3 bucket with an
+// optional key prefix (s3://bucket/prefix). S3 credentials, the region and an
+// endpoint of an S3-compatible API are read from the standard AWS environment
+// variables.
+func Open(location string) (Cache, error) {
+	if strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://") {
+		return HTTP{URL: strings.TrimRight(location, "/")}, nil
+	}
+
+	if !strings.HasPrefix(location, "s3://") {
+		return Local{Dir: location}, nil
+	}
+
+	u, err := url.Parse(location)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid S3 cache location '%s', expected s3://bucket/prefix", location)
+	}
+
+	s3 := S3{
+		Endpoint:     os.Getenv("AWS_ENDPOINT_URL_S3"),
+		Region:       os.Getenv("AWS_REGION"),
+		Bucket:       u.Host,
+		Prefix:       strings.TrimPrefix(u.Path, "/"),
+		AccessKey:    os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretKey:    os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken: os.Getenv("AWS_SESSION_TOKEN"),
+	}
+
+	if s3.Endpoint == "" {
+		s3.Endpoint = os.Getenv("AWS_ENDPOINT_URL")
+	}
+	if s3.Region == "" {
+		s3.Region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	if s3.Region == "" {
+		s3.Region = "us-east-1"
+	}
+	if s3.Endpoint == "" {
+		s3.Endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", s3.Region)
+	}
+	if s3.AccessKey == "" || s3.SecretKey == "" {
+		return nil, fmt.Errorf("cannot use S3 cache: AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY are required")
+	}
+
+	return s3, nil
+}
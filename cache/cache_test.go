@@ -0,0 +1,61 @@
+package cache
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestOpen(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "key")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_SESSION_TOKEN", "")
+	t.Setenv("AWS_REGION", "eu-west-1")
+	t.Setenv("AWS_DEFAULT_REGION", "")
+	t.Setenv("AWS_ENDPOINT_URL", "")
+	t.Setenv("AWS_ENDPOINT_URL_S3", "")
+
+	tests := []struct {
+		name     string
+		location string
+		want     Cache
+		wantErr  bool
+	}{
+		{"opens a local directory", ".monobuild/cache", Local{Dir: ".monobuild/cache"}, false},
+		{"opens an HTTP server", "http://cache:8080/", HTTP{URL: "http://cache:8080"}, false},
+		{
+			"opens an S3 bucket",
+			"s3://builds/monobuild/",
+			S3{
+				Endpoint:  "https://s3.eu-west-1.amazonaws.com",
+				Region:    "eu-west-1",
+				Bucket:    "builds",
+				Prefix:    "monobuild/",
+				AccessKey: "key",
+				SecretKey: "secret",
+			},
+			false,
+		},
+		{"fails without an S3 bucket", "s3:///monobuild", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Open(tt.location)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Open() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Open() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpen_credentials(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+
+	if _, err := Open("s3://builds"); err == nil {
+		t.Errorf("Open() expected an error without S3 credentials")
+	}
+}
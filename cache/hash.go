@@ -0,0 +1,79 @@
+package cache
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/charypar/monobuild/graph"
+)
+
+// FileHash returns the git blob object ID of a file's content, which is the
+// same as the ID of the file in a git tree
+func FileHash(path string) (string, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha1.New()
+	fmt.Fprintf(hash, "blob %d\x00", len(content))
+	hash.Write(content)
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// Hashes computes a content hash of each component in the dependency graph,
+// Merkle-style, from the hashes of the files in the component's directory and
+// the hashes of its dependencies. A change of a file changes the hash of its
+// component and of everything depending on it, directly or transitively.
+// Files are given as a map of paths to their hashes, only files for which
+// counts returns true, given their path relative to the component, are
+// counted (all files if counts is nil).
+func Hashes(dependencies graph.Graph, files map[string]string, counts func(path string) bool) (map[string]string, error) {
+	levels, err := dependencies.Levels()
+	if err != nil {
+		return nil, fmt.Errorf("cannot hash components: %s", err)
+	}
+
+	// dependencies are hashed before their dependents
+	components := dependencies.Vertices()
+	sort.SliceStable(components, func(i, j int) bool {
+		return levels[components[i]] < levels[components[j]]
+	})
+
+	paths := make([]string, 0, len(files))
+	for p := range files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	hashes := make(map[string]string, len(components))
+	for _, c := range components {
+		hash := sha256.New()
+		fmt.Fprintf(hash, "component %s\n", c)
+
+		for _, p := range paths {
+			if !strings.HasPrefix(p, c+"/") {
+				continue
+			}
+
+			relative := strings.TrimPrefix(p, c+"/")
+			if counts == nil || counts(relative) {
+				fmt.Fprintf(hash, "file %s %s\n", relative, files[p])
+			}
+		}
+
+		for _, d := range dependencies.Children([]string{c}) {
+			fmt.Fprintf(hash, "dependency %s %s\n", d, hashes[d])
+		}
+
+		hashes[c] = hex.EncodeToString(hash.Sum(nil))
+	}
+
+	return hashes, nil
+}
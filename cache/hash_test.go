@@ -0,0 +1,108 @@
+package cache
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/charypar/monobuild/graph"
+)
+
+var exampleDependencies = graph.New(map[string][]graph.Edge{
+	"app1": {{Label: "lib1", Colour: graph.Weak}, {Label: "lib2", Colour: graph.Strong}},
+	"app2": {{Label: "lib2", Colour: graph.Weak}},
+	"lib1": {{Label: "lib3", Colour: graph.Weak}},
+	"lib2": {},
+	"lib3": {},
+})
+
+var exampleFiles = map[string]string{
+	"app1/main.go": "a1",
+	"app2/main.go": "a2",
+	"lib1/lib.go":  "l1",
+	"lib2/lib.go":  "l2",
+	"lib3/lib.go":  "l3",
+	"README.md":    "r",
+}
+
+func TestFileHash(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file.txt")
+	if err := ioutil.WriteFile(path, []byte("hello\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := FileHash(path)
+	if err != nil {
+		t.Fatalf("FileHash() error = %v", err)
+	}
+
+	want := "ce013625030ba8dba906f756967f9e9ca394464a"
+	if got != want {
+		t.Errorf("FileHash() = %v, want %v", got, want)
+	}
+}
+
+func TestHashes(t *testing.T) {
+	noMain := func(path string) bool { return path != "main.go" }
+
+	tests := []struct {
+		name    string
+		counts  func(path string) bool
+		changes map[string]string
+		want    []string
+	}{
+		{"is stable", nil, map[string]string{}, []string{}},
+		{"ignores files outside components", nil, map[string]string{"README.md": "changed"}, []string{}},
+		{"changes with a file of an application", nil, map[string]string{"app2/main.go": "changed"}, []string{"app2"}},
+		{"changes with a transitive dependency", nil, map[string]string{"lib3/lib.go": "changed"}, []string{"app1", "lib1", "lib3"}},
+		{"changes with a new file", nil, map[string]string{"lib2/new.go": "new"}, []string{"app1", "app2", "lib2"}},
+		{"ignores files which don't count", noMain, map[string]string{"app2/main.go": "changed"}, []string{}},
+		{"changes with files which count", noMain, map[string]string{"app2/main.go": "changed", "lib2/lib.go": "changed"}, []string{"app1", "app2", "lib2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base, err := Hashes(exampleDependencies, exampleFiles, tt.counts)
+			if err != nil {
+				t.Fatalf("Hashes() error = %v", err)
+			}
+
+			files := make(map[string]string, len(exampleFiles))
+			for p, h := range exampleFiles {
+				files[p] = h
+			}
+			for p, h := range tt.changes {
+				files[p] = h
+			}
+
+			hashes, err := Hashes(exampleDependencies, files, tt.counts)
+			if err != nil {
+				t.Fatalf("Hashes() error = %v", err)
+			}
+
+			got := []string{}
+			for c, h := range hashes {
+				if base[c] != h {
+					got = append(got, c)
+				}
+			}
+			sort.Strings(got)
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Hashes() changed %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHashes_cycle(t *testing.T) {
+	cyclic := graph.New(map[string][]graph.Edge{
+		"a": {{Label: "b", Colour: graph.Weak}},
+		"b": {{Label: "a", Colour: graph.Weak}},
+	})
+
+	if _, err := Hashes(cyclic, map[string]string{}, nil); err == nil {
+		t.Errorf("Hashes() expected an error for a cycle")
+	}
+}
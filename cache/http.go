@@ -0,0 +1,129 @@
+package cache
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+)
+
+// HTTP is a cache on an HTTP server, which returns the value stored under
+// a key for a GET request to <URL>/<key> (or 404 Not Found if there is none)
+// and stores the body of a PUT request to the same URL. 'monobuild
+// cache-server' implements the protocol.
+type HTTP struct {
+	URL    string
+	Client *http.Client // Uses http.DefaultClient if nil
+}
+
+func (h HTTP) client() *http.Client {
+	if h.Client == nil {
+		return http.DefaultClient
+	}
+
+	return h.Client
+}
+
+// Get reads the value stored under a key, if there is one
+func (h HTTP) Get(key string) ([]byte, bool, error) {
+	if err := checkKey(key); err != nil {
+		return nil, false, err
+	}
+
+	response, err := h.client().Get(h.URL + "/" + key)
+	if err != nil {
+		return nil, false, fmt.Errorf("cannot read from cache: %s", err)
+	}
+	defer response.Body.Close()
+
+	return readResponse(response)
+}
+
+// Put stores a value under a key
+func (h HTTP) Put(key string, value []byte) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+
+	request, err := http.NewRequest(http.MethodPut, h.URL+"/"+key, bytes.NewReader(value))
+	if err != nil {
+		return fmt.Errorf("cannot write to cache: %s", err)
+	}
+
+	response, err := h.client().Do(request)
+	if err != nil {
+		return fmt.Errorf("cannot write to cache: %s", err)
+	}
+	defer response.Body.Close()
+
+	return checkResponse(response)
+}
+
+// readResponse reads the value from the response to a GET request
+func readResponse(response *http.Response) ([]byte, bool, error) {
+	if response.StatusCode == http.StatusNotFound {
+		return nil, false, nil
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return nil, false, fmt.Errorf("cannot read from cache: %s", response.Status)
+	}
+
+	value, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, false, fmt.Errorf("cannot read from cache: %s", err)
+	}
+
+	return value, true, nil
+}
+
+// checkResponse checks the response to a PUT request succeeded
+func checkResponse(response *http.Response) error {
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("cannot write to cache: %s", response.Status)
+	}
+
+	return nil
+}
+
+// Server serves a cache over HTTP, for the HTTP cache
+func Server(c Cache) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.URL.Path[1:]
+		if err := checkKey(key); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+			value, ok, err := c.Get(key)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+
+			w.Write(value)
+		case http.MethodPut:
+			value, err := ioutil.ReadAll(r.Body)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			if err := c.Put(key, value); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.Header().Set("Allow", "GET, HEAD, PUT")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+}
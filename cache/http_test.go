@@ -0,0 +1,69 @@
+package cache
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestHTTP(t *testing.T) {
+	server := httptest.NewServer(Server(Local{Dir: t.TempDir()}))
+	defer server.Close()
+
+	cache := HTTP{URL: server.URL}
+
+	if _, ok, err := cache.Get("abc"); ok || err != nil {
+		t.Errorf("Get() of a missing key = %v, %v, want false, nil", ok, err)
+	}
+
+	if err := cache.Put("abc", []byte("app1")); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	value, ok, err := cache.Get("abc")
+	if !ok || err != nil {
+		t.Fatalf("Get() = %v, %v, want true, nil", ok, err)
+	}
+	if !reflect.DeepEqual(value, []byte("app1")) {
+		t.Errorf("Get() = %s, want app1", value)
+	}
+}
+
+func TestServer(t *testing.T) {
+	server := httptest.NewServer(Server(Local{Dir: t.TempDir()}))
+	defer server.Close()
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		want   int
+	}{
+		{"stores a value", http.MethodPut, "/abc", http.StatusNoContent},
+		{"finds a value", http.MethodGet, "/abc", http.StatusOK},
+		{"checks a value", http.MethodHead, "/abc", http.StatusOK},
+		{"misses a value", http.MethodGet, "/def", http.StatusNotFound},
+		{"rejects invalid keys", http.MethodPut, "/a/b", http.StatusBadRequest},
+		{"rejects other methods", http.MethodDelete, "/abc", http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request, err := http.NewRequest(tt.method, server.URL+tt.path, strings.NewReader("app1"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			response, err := http.DefaultClient.Do(request)
+			if err != nil {
+				t.Fatal(err)
+			}
+			response.Body.Close()
+
+			if response.StatusCode != tt.want {
+				t.Errorf("%s %s = %d, want %d", tt.method, tt.path, response.StatusCode, tt.want)
+			}
+		})
+	}
+}
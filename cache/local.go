@@ -0,0 +1,59 @@
+package cache
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+var validKey = regexp.MustCompile("^[A-Za-z0-9_.-]+$")
+
+// checkKey makes sure a key is safe to use as a file name or in a URL
+func checkKey(key string) error {
+	if !validKey.MatchString(key) || key == "." || key == ".." {
+		return fmt.Errorf("invalid cache key '%s'", key)
+	}
+
+	return nil
+}
+
+// Local is a cache in a local directory, with a file for each key
+type Local struct {
+	Dir string
+}
+
+// Get reads the value stored under a key, if there is one
+func (l Local) Get(key string) ([]byte, bool, error) {
+	if err := checkKey(key); err != nil {
+		return nil, false, err
+	}
+
+	value, err := ioutil.ReadFile(filepath.Join(l.Dir, key))
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("cannot read from cache: %s", err)
+	}
+
+	return value, true, nil
+}
+
+// Put stores a value under a key
+func (l Local) Put(key string, value []byte) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(l.Dir, 0755); err != nil {
+		return fmt.Errorf("cannot create cache directory: %s", err)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(l.Dir, key), value, 0644); err != nil {
+		return fmt.Errorf("cannot write to cache: %s", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,32 @@
+package cache
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLocal(t *testing.T) {
+	local := Local{Dir: t.TempDir() + "/cache"}
+
+	if _, ok, err := local.Get("abc"); ok || err != nil {
+		t.Errorf("Get() of a missing key = %v, %v, want false, nil", ok, err)
+	}
+
+	if err := local.Put("abc", []byte("app1")); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	value, ok, err := local.Get("abc")
+	if !ok || err != nil {
+		t.Fatalf("Get() = %v, %v, want true, nil", ok, err)
+	}
+	if !reflect.DeepEqual(value, []byte("app1")) {
+		t.Errorf("Get() = %s, want app1", value)
+	}
+
+	for _, key := range []string{"", "..", "a/b", "../abc"} {
+		if err := local.Put(key, []byte{}); err == nil {
+			t.Errorf("Put() expected an error for key '%s'", key)
+		}
+	}
+}
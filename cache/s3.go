@@ -0,0 +1,193 @@
+package cache
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// S3 is a cache in a bucket of S3 or an S3-compatible API, with an object for
+// each key. Objects are addressed path-style, <Endpoint>/<Bucket>/<Prefix><key>,
+// and requests are signed with AWS Signature Version 4.
+type S3 struct {
+	Endpoint     string // e.g. https://s3.eu-west-1.amazonaws.com
+	Region       string
+	Bucket       string
+	Prefix       string // Prefix of object keys, e.g. 'monobuild/'
+	AccessKey    string
+	SecretKey    string
+	SessionToken string       // Optional, for temporary credentials
+	Client       *http.Client // Uses http.DefaultClient if nil
+}
+
+func (s S3) client() *http.Client {
+	if s.Client == nil {
+		return http.DefaultClient
+	}
+
+	return s.Client
+}
+
+// request creates a signed request for the object stored under a key
+func (s S3) request(method string, key string, body []byte) (*http.Request, error) {
+	url := fmt.Sprintf("%s/%s/%s%s", strings.TrimRight(s.Endpoint, "/"), s.Bucket, s.Prefix, key)
+
+	request, err := http.NewRequest(method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	if s.SessionToken != "" {
+		request.Header.Set("X-Amz-Security-Token", s.SessionToken)
+	}
+
+	s.sign(request, body, time.Now())
+	return request, nil
+}
+
+// Get reads the value stored under a key, if there is one
+func (s S3) Get(key string) ([]byte, bool, error) {
+	if err := checkKey(key); err != nil {
+		return nil, false, err
+	}
+
+	request, err := s.request(http.MethodGet, key, nil)
+	if err != nil {
+		return nil, false, fmt.Errorf("cannot read from cache: %s", err)
+	}
+
+	response, err := s.client().Do(request)
+	if err != nil {
+		return nil, false, fmt.Errorf("cannot read from cache: %s", err)
+	}
+	defer response.Body.Close()
+
+	return readResponse(response)
+}
+
+// Put stores a value under a key
+func (s S3) Put(key string, value []byte) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+
+	request, err := s.request(http.MethodPut, key, value)
+	if err != nil {
+		return fmt.Errorf("cannot write to cache: %s", err)
+	}
+
+	response, err := s.client().Do(request)
+	if err != nil {
+		return fmt.Errorf("cannot write to cache: %s", err)
+	}
+	defer response.Body.Close()
+
+	return checkResponse(response)
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+
+	return mac.Sum(nil)
+}
+
+func sha256Hex(data []byte) string {
+	hash := sha256.Sum256(data)
+
+	return hex.EncodeToString(hash[:])
+}
+
+// uriEncode encodes a string as required by Signature Version 4, leaving out
+// the slashes of paths if encoding a path
+func uriEncode(s string, path bool) string {
+	var b strings.Builder
+
+	for _, c := range []byte(s) {
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~', c == '/' && path:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+
+	return b.String()
+}
+
+// sign signs a request with AWS Signature Version 4, covering the host and
+// all the headers of the request
+func (s S3) sign(request *http.Request, body []byte, now time.Time) {
+	now = now.UTC()
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	request.Header.Set("X-Amz-Date", now.Format("20060102T150405Z"))
+	request.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": request.URL.Host}
+	for name, values := range request.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&canonicalHeaders, "%s:%s\n", name, headers[name])
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	query := request.URL.Query()
+	params := make([]string, 0, len(query))
+	for name, values := range query {
+		for _, v := range values {
+			params = append(params, uriEncode(name, false)+"="+uriEncode(v, false))
+		}
+	}
+	sort.Strings(params)
+
+	path := request.URL.Path
+	if path == "" {
+		path = "/"
+	}
+
+	canonicalRequest := strings.Join([]string{
+		request.Method,
+		uriEncode(path, true),
+		strings.Join(params, "&"),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := fmt.Sprintf("%s/%s/s3/aws4_request", date, s.Region)
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		now.Format("20060102T150405Z"),
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.SecretKey), date)
+	key = hmacSHA256(key, s.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	request.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.AccessKey, scope, signedHeaders, signature,
+	))
+}
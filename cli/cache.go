@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/charypar/monobuild/cache"
+	"github.com/charypar/monobuild/config"
+	"github.com/charypar/monobuild/diff"
+	"github.com/charypar/monobuild/graph"
+	"github.com/charypar/monobuild/manifests"
+)
+
+// hashes computes the content hashes of the components from the files
+// tracked by git, in the working tree or in a revision if given
+func hashes(dependencies graph.Graph, conf config.HashFiles, revision string) (map[string]string, error) {
+	components := dependencies.Vertices()
+	files := map[string]string{}
+
+	if revision != "" {
+		tree, err := diff.TreeFiles(revision)
+		if err != nil {
+			return nil, err
+		}
+
+		for f, hash := range tree {
+			if len(manifests.FilterComponents(components, []string{f})) > 0 {
+				files[f] = hash
+			}
+		}
+
+		return cache.Hashes(dependencies, files, conf.Counts)
+	}
+
+	tracked, err := diff.TrackedFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, f := range tracked {
+		if len(manifests.FilterComponents(components, []string{f})) < 1 {
+			continue
+		}
+
+		info, err := os.Stat(f)
+		if os.IsNotExist(err) || (err == nil && info.IsDir()) {
+			continue // deleted but not yet staged, or a submodule
+		}
+
+		hash, err := cache.FileHash(f)
+		if err != nil {
+			return nil, fmt.Errorf("cannot hash file: %s", err)
+		}
+
+		files[f] = hash
+	}
+
+	return cache.Hashes(dependencies, files, conf.Counts)
+}
+
+// Uncached removes the components whose content hash has a successful build
+// recorded in the cache from the selection of a result
+func Uncached(sources Manifests, result Result, c cache.Cache) (Result, error) {
+	hs, err := hashes(result.Dependencies, sources.Config.Hash, "")
+	if err != nil {
+		return Result{}, err
+	}
+
+	selection := make([]string, 0, len(result.Selection))
+	for _, s := range result.Selection {
+		_, built, err := c.Get(hs[s])
+		if err != nil {
+			return Result{}, err
+		}
+
+		if !built {
+			selection = append(selection, s)
+		}
+	}
+
+	result.Selection = selection
+	return result, nil
+}
+
+// Record is 'monobuild record'
+// It records successful builds of components in the cache, under their
+// current content hashes.
+func Record(sources Manifests, components []string, c cache.Cache) error {
+	repo, err := loadManifests(sources)
+	if err != nil {
+		return err
+	}
+
+	hs, err := hashes(repo.dependencies, sources.Config.Hash, "")
+	if err != nil {
+		return err
+	}
+
+	for _, component := range components {
+		hash, ok := hs[component]
+		if !ok {
+			return fmt.Errorf("cannot record build of unknown component '%s'", component)
+		}
+
+		if err := c.Put(hash, []byte(component+"\n")); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/charypar/monobuild/rules"
+)
+
+// Check is 'monobuild check'
+// It reports dependencies breaking any of the architectural rules.
+func Check(sources Manifests, constraints []rules.Rule) ([]Problem, error) {
+	repo, err := loadManifests(sources)
+	if err != nil {
+		return nil, err
+	}
+
+	locations, err := locateDependencies(sources)
+	if err != nil {
+		return nil, err
+	}
+
+	problems := []Problem{}
+
+	for _, v := range rules.Check(constraints, repo.dependencies, repo.tags(sources.Config)) {
+		kind := "dependency"
+		if v.Strong {
+			kind = "strong dependency"
+		}
+
+		problem := Problem{
+			Component: v.Component,
+			Message:   fmt.Sprintf("%s on '%s' breaks rule '%s' (%s)", kind, v.Dependency, v.Rule, v.Rule.Location),
+		}
+		if location, ok := locations.Find(v.Component, v.Dependency); ok {
+			problem.Location = location.String()
+		}
+
+		problems = append(problems, problem)
+	}
+
+	sortProblems(problems)
+
+	return problems, nil
+}
@@ -2,12 +2,16 @@ package cli
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/bmatcuk/doublestar"
+	"github.com/charypar/monobuild/config"
 	"github.com/charypar/monobuild/diff"
 	"github.com/charypar/monobuild/graph"
 	"github.com/charypar/monobuild/manifests"
+	"github.com/charypar/monobuild/report"
+	"github.com/charypar/monobuild/set"
 )
 
 func joinErrors(message string, errors []error) error {
@@ -19,37 +23,152 @@ func joinErrors(message string, errors []error) error {
 	return fmt.Errorf("%s\n%s", message, strings.Join(errstrings, "\n"))
 }
 
-func loadManifests(globPattern string, repoManifest string) ([]string, graph.Graph, graph.Graph, error) {
-	components, deps, errs := []string{}, manifests.Dependencies{}, []error{}
+// Manifests holds the sources the dependency graph is loaded from
+type Manifests struct {
+	DependencyFilesGlob string // Search pattern for dependency manifests
+	RepoManifest        string // Full repository manifest, used instead of dependency manifests
+	RepoManifestFile    string // Name of the file the repository manifest was read from
+	ComponentFilesGlob  string // Search pattern for structured component manifests
+	GoImports           bool   // Infer weak dependencies from Go imports
+	GoModulesGlob       string // Search pattern for go.mod files used to resolve Go imports
+	Config              config.Config
+}
 
-	if len(repoManifest) > 0 {
-		components, deps, errs = manifests.ReadRepoManifest(repoManifest, false)
-	} else {
-		manifestFiles, err := doublestar.Glob(globPattern)
-		if err != nil {
-			return []string{}, graph.Graph{}, graph.Graph{}, fmt.Errorf("error finding dependency manifests: %s", err)
+func manifestFiles(sources Manifests) ([]string, error) {
+	dependencyFiles, err := doublestar.Glob(sources.DependencyFilesGlob)
+	if err != nil {
+		return nil, fmt.Errorf("error finding dependency manifests: %s", err)
+	}
+
+	if sources.ComponentFilesGlob == "" {
+		return dependencyFiles, nil
+	}
+
+	componentFiles, err := doublestar.Glob(sources.ComponentFilesGlob)
+	if err != nil {
+		return nil, fmt.Errorf("error finding component manifests: %s", err)
+	}
+
+	return append(dependencyFiles, componentFiles...), nil
+}
+
+func readDeclared(sources Manifests) ([]manifests.Component, manifests.Dependencies, []error) {
+	if len(sources.RepoManifest) > 0 {
+		names, deps, errs := manifests.ReadRepoManifest(sources.RepoManifest, false)
+
+		// the repository manifest carries no metadata
+		components := make([]manifests.Component, 0, len(names))
+		for _, name := range names {
+			components = append(components, manifests.Component{Name: name})
 		}
 
-		// Find components and dependencies
-		components, deps, errs = manifests.Read(manifestFiles, false)
+		return components, deps, errs
 	}
 
+	files, err := manifestFiles(sources)
+	if err != nil {
+		return nil, manifests.Dependencies{}, []error{err}
+	}
+
+	return manifests.ReadComponents(files, false)
+}
+
+func readGoImports(sources Manifests, components []string) ([]string, manifests.Dependencies, error) {
+	moduleFiles, err := doublestar.Glob(sources.GoModulesGlob)
+	if err != nil {
+		return nil, manifests.Dependencies{}, fmt.Errorf("error finding go modules: %s", err)
+	}
+
+	goComponents, imports, errs := manifests.ReadGoImports(components, moduleFiles)
 	if errs != nil {
-		return []string{}, graph.Graph{}, graph.Graph{}, fmt.Errorf("%s", joinErrors("cannot load dependencies:", errs))
+		return nil, manifests.Dependencies{}, joinErrors("cannot infer dependencies from Go imports:", errs)
+	}
+
+	return goComponents, imports, nil
+}
+
+// repository holds the components found in the manifests and their
+// dependency graphs
+type repository struct {
+	components   []string
+	metadata     map[string]manifests.Component
+	dependencies graph.Graph
+	schedule     graph.Graph
+}
+
+// tags returns the tags of each component, both declared in its manifest
+// and assigned by the configuration
+func (r repository) tags(conf config.Config) map[string]set.Set {
+	tags := make(map[string]set.Set, len(r.components))
+
+	for _, c := range r.components {
+		declared := append([]string{}, r.metadata[c].Tags...)
+		tags[c] = set.New(append(declared, conf.TagsOf(c)...))
+	}
+
+	return tags
+}
+
+func loadManifests(sources Manifests) (repository, error) {
+	// Find components and dependencies
+	declared, deps, errs := readDeclared(sources)
+	if errs != nil {
+		return repository{}, fmt.Errorf("%s", joinErrors("cannot load dependencies:", errs))
+	}
+
+	components := manifests.Names(declared)
+	metadata := make(map[string]manifests.Component, len(declared))
+	for _, c := range declared {
+		metadata[c.Name] = c
+	}
+
+	if sources.GoImports {
+		_, imports, err := readGoImports(sources, components)
+		if err != nil {
+			return repository{}, err
+		}
+
+		deps = deps.Merge(imports)
 	}
 
 	dependencies := deps.AsGraph()
 	buildSchedule := dependencies.FilterEdges([]int{graph.Strong})
+	repo := repository{components, metadata, dependencies, buildSchedule}
+
+	// metadata carries both declared and configured tags
+	for c, tags := range repo.tags(sources.Config) {
+		component := metadata[c]
+		component.Tags = tags.AsStrings()
+		sort.Strings(component.Tags)
+		metadata[c] = component
+	}
 
-	return components, dependencies, buildSchedule, nil
+	return repo, nil
 }
 
 // Scope of selection
 type Scope struct {
-	Scope    string
-	TopLevel bool
+	Scope        string
+	DependentsOf string   // Selector of components to list dependents of
+	Depth        int      // Limits the depth of scope and dependents, unlimited if less than 1
+	Strength     Strength // Limits dependents to those connected with dependencies of a strength
+	TopLevel     bool
+	Tags         []string // Tag expressions, components matching any of them are selected
+	ExcludeTags  []string // Tag expressions, components matching any of them are excluded
 }
 
+// Strength of dependencies to follow
+type Strength int
+
+// AnyStrength follows all dependencies
+var AnyStrength Strength
+
+// WeakOnly only follows weak dependencies
+var WeakOnly Strength = 1
+
+// StrongOnly only follows strong dependencies
+var StrongOnly Strength = 2
+
 // OutputFormat hold the format of text output
 type OutputFormat int
 
@@ -61,6 +180,15 @@ var Text OutputFormat = 1
 // Dot is the DOT graph language, see https://graphviz.gitlab.io/_pages/doc/info/lang.html
 var Dot OutputFormat = 2
 
+// Mermaid is a Mermaid flowchart, see https://mermaid.js.org/syntax/flowchart.html
+var Mermaid OutputFormat = 3
+
+// PlantUML is a PlantUML diagram, see https://plantuml.com
+var PlantUML OutputFormat = 4
+
+// HTML is a self-contained HTML page with an interactive graph viewer
+var HTML OutputFormat = 5
+
 // OutputType holds the kind of output to show
 type OutputType int
 
@@ -78,53 +206,90 @@ var Full OutputType = 3
 // The options are not always independent, e.g. the Dot format has different output
 // for Schedule type and Dependencies type.
 type OutputOptions struct {
-	Format OutputFormat // Output text format
-	Type   OutputType   // Type of output shown
+	Format    OutputFormat // Output text format
+	Type      OutputType   // Type of output shown
+	Reduce    bool         // Leave out edges implied by other paths in the output
+	Highlight bool         // Show the whole graph highlighting changed and selected components (not in Text)
+	Clusters  bool         // Group components by directory when highlighting
 }
 
-// Format output for the command line, filtering nodes only to those in the 'filter' slice.
+// Result holds the outcome of a command, which can be formatted for output
+type Result struct {
+	Dependencies graph.Graph
+	Schedule     graph.Graph
+	Selection    []string                       // Selected components
+	Changed      []string                       // Components with changed files
+	ChangedFiles []string                       // Changed files
+	Components   map[string]manifests.Component // Metadata of all the components, including configured tags
+}
+
+// Format output for the command line, filtering nodes only to those selected.
 // Output options can be set using 'opts'
-func Format(dependencies graph.Graph, schedule graph.Graph, filter []string, opts OutputOptions) string {
-	if opts.Format == Dot && opts.Type == Dependencies {
-		return dependencies.Dot(filter)
+func Format(result Result, opts OutputOptions) (string, error) {
+	dependencies, schedule, filter := result.Dependencies, result.Schedule, result.Selection
+
+	highlight := graph.Highlight{}
+	if opts.Highlight && opts.Format != Text {
+		// show the whole graph, highlighting the selection
+		highlight = graph.Highlight{Changed: result.Changed, Impacted: filter, Clusters: opts.Clusters}
+		filter = dependencies.Vertices()
+	}
+
+	if opts.Reduce {
+		// reduce only the selected part, paths through other vertices are not shown
+		dependencies = dependencies.Subgraph(filter).TransitiveReduction()
+		schedule = schedule.Subgraph(filter).TransitiveReduction()
 	}
 
-	if opts.Format == Dot {
-		return schedule.DotSchedule(filter)
+	showSchedule := opts.Type == Schedule
+
+	switch {
+	case opts.Format == HTML:
+		return report.HTML(dependencies, schedule, filter, highlight)
+	case opts.Format == Dot && opts.Highlight && showSchedule:
+		return schedule.DotHighlight(highlight, true), nil
+	case opts.Format == Dot && opts.Highlight:
+		return dependencies.DotHighlight(highlight, false), nil
+	case opts.Format == Dot && showSchedule:
+		return schedule.DotSchedule(filter), nil
+	case opts.Format == Dot:
+		return dependencies.Dot(filter), nil
+	case opts.Format == Mermaid && showSchedule:
+		return schedule.MermaidSchedule(filter, highlight), nil
+	case opts.Format == Mermaid:
+		return dependencies.Mermaid(filter, highlight), nil
+	case opts.Format == PlantUML && showSchedule:
+		return schedule.PlantUMLSchedule(filter, highlight), nil
+	case opts.Format == PlantUML:
+		return dependencies.PlantUML(filter, highlight), nil
 	}
 
 	if opts.Type == Dependencies {
-		return dependencies.Text(filter, false)
+		return dependencies.Text(filter, false), nil
 	}
 
 	if opts.Type == Full {
-		return dependencies.Text(filter, true)
+		return dependencies.Text(filter, true), nil
 	}
 
-	return schedule.Text(filter, false)
+	return schedule.Text(filter, false), nil
 }
 
 // Print is 'monobuild print'
-func Print(dependencyFilesGlob string, scope Scope, repoManifest string) (graph.Graph, graph.Graph, []string, error) {
-	components, dependencies, buildSchedule, err := loadManifests(dependencyFilesGlob, repoManifest)
+func Print(sources Manifests, scope Scope) (Result, error) {
+	repo, err := loadManifests(sources)
 	if err != nil {
-		return graph.Graph{}, graph.Graph{}, []string{}, err
+		return Result{}, err
 	}
 
-	selection := newFilter(components, components)
+	selection := newFilter(repo.components, repo.components)
 
-	if scope.Scope != "" {
-		err = selection.scopeTo(scope.Scope, dependencies)
-		if err != nil {
-			return graph.Graph{}, graph.Graph{}, []string{}, err
-		}
-	}
-
-	if scope.TopLevel {
-		selection.onlyTop(dependencies)
+	err = selection.applyScope(scope, repo, sources.Config)
+	if err != nil {
+		return Result{}, err
 	}
 
-	return dependencies, buildSchedule, selection.AsStrings(), nil
+	return Result{repo.dependencies, repo.schedule, selection.AsStrings(), []string{}, []string{}, repo.metadata}, nil
 }
 
 // DiffMode is the diff command mode, the kind of branch we're working on, or
@@ -140,12 +305,20 @@ var MainBranch DiffMode = 2
 // Direct indicates a directly supplied file list
 var Direct DiffMode = 3
 
+// SinceLastSuccess compares each component with its last successful build
+var SinceLastSuccess DiffMode = 4
+
+// MergeQueue is a merge queue mode, comparing a merge commit with its parents
+var MergeQueue DiffMode = 5
+
 // DiffContext holds configuration for the Diff command
 type DiffContext struct {
 	Mode         DiffMode // I realy want tagged unions right now.
-	BaseBranch   string
-	BaseCommit   string
+	BaseBranches []string
+	BaseCommits  []string
+	Intersect    bool // Only files changed against all the bases, rather than any of them
 	ChangedFiles []string
+	Markers      diff.Markers // Last successful builds, for SinceLastSuccess
 }
 
 func diffModeFrom(diffContext DiffContext) diff.Mode {
@@ -155,59 +328,70 @@ func diffModeFrom(diffContext DiffContext) diff.Mode {
 		diffMode = diff.Feature
 	case MainBranch:
 		diffMode = diff.Main
+	case MergeQueue:
+		diffMode = diff.MergeQueue
+	}
+
+	combine := diff.Union
+	if diffContext.Intersect {
+		combine = diff.Intersection
 	}
 
 	return diff.Mode{
-		Mode:       diffMode,
-		BaseBranch: diffContext.BaseBranch,
-		BaseCommit: diffContext.BaseCommit,
+		Mode:         diffMode,
+		BaseBranches: diffContext.BaseBranches,
+		BaseCommits:  diffContext.BaseCommits,
+		Combine:      combine,
 	}
 }
 
 // Diff is 'monobuild diff'
-func Diff(dependencyFilesGlob string, diffContext DiffContext, scope Scope, includeStrong bool, repoManifest string) (graph.Graph, graph.Graph, []string, error) {
-	components, dependencies, buildSchedule, err := loadManifests(dependencyFilesGlob, repoManifest)
+func Diff(sources Manifests, diffContext DiffContext, scope Scope, includeStrong bool) (Result, error) {
+	repo, err := loadManifests(sources)
 	if err != nil {
-		return graph.Graph{}, graph.Graph{}, []string{}, err
+		return Result{}, err
 	}
 
 	// Get changed files
-	var changes []string
+	var changes, changedComponents, impacted []string
 
-	if diffContext.Mode == Direct {
+	switch diffContext.Mode {
+	case SinceLastSuccess:
+		// Each component has its own base
+		changes, changedComponents, impacted, err = sinceLastSuccess(repo, diffContext.Markers)
+		if err != nil {
+			return Result{}, fmt.Errorf("cannot find changes: %s", err)
+		}
+	case Direct:
 		// Used supplied list
 		changes = diffContext.ChangedFiles
-	} else {
+	default:
 		// Get changes from git
 		changes, err = diff.ChangedFiles(diffModeFrom(diffContext))
 		if err != nil {
-			return graph.Graph{}, graph.Graph{}, []string{}, fmt.Errorf("cannot find changes: %s", err)
+			return Result{}, fmt.Errorf("cannot find changes: %s", err)
 		}
 	}
 
 	// Find impacted components
-	changedComponents := manifests.FilterComponents(components, changes)
-	impacted := diff.Impacted(changedComponents, dependencies)
+	if diffContext.Mode != SinceLastSuccess {
+		changedComponents = manifests.FilterComponents(repo.components, changes)
+		impacted = diff.Impacted(changedComponents, repo.dependencies)
+	}
 
 	// Select what to show
 
-	selection := newFilter(components, impacted)
-
-	if scope.Scope != "" {
-		err = selection.scopeTo(scope.Scope, dependencies)
-		if err != nil {
-			return graph.Graph{}, graph.Graph{}, []string{}, err
-		}
-	}
+	selection := newFilter(repo.components, impacted)
 
-	if scope.TopLevel {
-		selection.onlyTop(dependencies)
+	err = selection.applyScope(scope, repo, sources.Config)
+	if err != nil {
+		return Result{}, err
 	}
 
 	// needs to come _after_ topLevel!
 	if includeStrong {
-		selection.addStrong(buildSchedule)
+		selection.addStrong(repo.schedule)
 	}
 
-	return dependencies, buildSchedule, selection.AsStrings(), nil
+	return Result{repo.dependencies, repo.schedule, selection.AsStrings(), changedComponents, changes, repo.metadata}, nil
 }
@@ -3,7 +3,9 @@ package cli
 import (
 	"fmt"
 
+	"github.com/charypar/monobuild/config"
 	"github.com/charypar/monobuild/graph"
+	"github.com/charypar/monobuild/selector"
 	"github.com/charypar/monobuild/set"
 )
 
@@ -19,13 +21,63 @@ func newFilter(components []string, filtered []string) filter {
 	return filter{components: componentsSet, filtered: filteredSet}
 }
 
-func (f *filter) scopeTo(component string, dependencies graph.Graph) error {
-	if !f.components.Has(component) {
-		return fmt.Errorf("cannot scope to '%s', not a component", component)
+// scopeTo restricts the selection to components selected by a selector
+// expression and their dependencies, up to a depth (if positive)
+func (f *filter) scopeTo(expression string, dependencies graph.Graph, depth int) error {
+	s, err := selector.Parse(expression)
+	if err != nil {
+		return fmt.Errorf("cannot scope to '%s': %s", expression, err)
 	}
 
-	scoped := set.New(append(dependencies.Descendants([]string{component}), component))
-	f.filtered = f.filtered.Intersect(scoped)
+	roots, err := s.Select(dependencies)
+	if err != nil {
+		return fmt.Errorf("cannot scope to '%s': %s", expression, err)
+	}
+
+	var scoped []string
+	if depth > 0 {
+		scoped = dependencies.DescendantsWithin(roots.AsStrings(), depth)
+	} else {
+		scoped = dependencies.Descendants(roots.AsStrings())
+	}
+
+	f.filtered = f.filtered.Intersect(roots.Union(set.New(scoped)))
+
+	return nil
+}
+
+// dependentsOf restricts the selection to components selected by a selector
+// expression and components depending on them, up to a depth (if positive),
+// optionally only following dependencies of a given strength
+func (f *filter) dependentsOf(expression string, dependencies graph.Graph, depth int, strength Strength) error {
+	s, err := selector.Parse(expression)
+	if err != nil {
+		return fmt.Errorf("cannot find dependents of '%s': %s", expression, err)
+	}
+
+	roots, err := s.Select(dependencies)
+	if err != nil {
+		return fmt.Errorf("cannot find dependents of '%s': %s", expression, err)
+	}
+
+	followed := dependencies
+	switch strength {
+	case WeakOnly:
+		followed = dependencies.FilterEdges([]int{graph.Weak})
+	case StrongOnly:
+		followed = dependencies.FilterEdges([]int{graph.Strong})
+	}
+
+	impactGraph := followed.Reverse()
+
+	var dependents []string
+	if depth > 0 {
+		dependents = impactGraph.DescendantsWithin(roots.AsStrings(), depth)
+	} else {
+		dependents = impactGraph.Descendants(roots.AsStrings())
+	}
+
+	f.filtered = f.filtered.Intersect(roots.Union(set.New(dependents)))
 
 	return nil
 }
@@ -45,6 +97,63 @@ func (f *filter) onlyTop(dependencies graph.Graph) {
 	f.filtered = f.filtered.Intersect(set.New(topLevel))
 }
 
+func matchesAnyTags(expressions []string, tags set.Set) bool {
+	for _, e := range expressions {
+		if config.MatchesTags(e, tags) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// withTags keeps components matching any of the include expressions (unless
+// there are none) and removes components matching any of the exclude expressions
+func (f *filter) withTags(tags map[string]set.Set, include []string, exclude []string) {
+	tagged := make([]string, 0, f.filtered.Size())
+
+	for _, c := range f.filtered.AsStrings() {
+		if len(include) > 0 && !matchesAnyTags(include, tags[c]) {
+			continue
+		}
+
+		if matchesAnyTags(exclude, tags[c]) {
+			continue
+		}
+
+		tagged = append(tagged, c)
+	}
+
+	f.filtered = set.New(tagged)
+}
+
+// applyScope narrows the selection down according to the scope
+func (f *filter) applyScope(scope Scope, repo repository, conf config.Config) error {
+	if scope.Scope != "" {
+		err := f.scopeTo(scope.Scope, repo.dependencies, scope.Depth)
+		if err != nil {
+			return err
+		}
+	}
+
+	if scope.DependentsOf != "" {
+		err := f.dependentsOf(scope.DependentsOf, repo.dependencies, scope.Depth, scope.Strength)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(scope.Tags) > 0 || len(scope.ExcludeTags) > 0 {
+		f.withTags(repo.tags(conf), scope.Tags, scope.ExcludeTags)
+	}
+
+	if scope.TopLevel {
+		f.onlyTop(repo.dependencies)
+	}
+
+	return nil
+}
+
 func (f *filter) addStrong(buildSchedule graph.Graph) {
 	strong := buildSchedule.Descendants(f.filtered.AsStrings())
 
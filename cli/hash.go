@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charypar/monobuild/config"
+	"github.com/charypar/monobuild/graph"
+)
+
+// Chain is the kind of dependencies whose fingerprints are chained into the
+// fingerprint of a component
+type Chain int
+
+// AllChain chains fingerprints of all dependencies
+var AllChain Chain = 1
+
+// WeakChain chains fingerprints of weak dependencies only
+var WeakChain Chain = 2
+
+// StrongChain chains fingerprints of strong dependencies only
+var StrongChain Chain = 3
+
+// Fingerprints holds fingerprints of components
+type Fingerprints map[string]string
+
+func (f Fingerprints) String() string {
+	components := make([]string, 0, len(f))
+	for c := range f {
+		components = append(components, c)
+	}
+	sort.Strings(components)
+
+	var b strings.Builder
+	for _, c := range components {
+		fmt.Fprintf(&b, "%s: %s\n", c, f[c])
+	}
+
+	return b.String()
+}
+
+// Hash is 'monobuild hash'
+// It computes fingerprints of components (all of them if none are given)
+// from the files tracked by git, in the working tree or in a revision if
+// given, and the fingerprints of their dependencies in the chain.
+func Hash(sources Manifests, components []string, chain Chain, revision string, files config.HashFiles) (Fingerprints, error) {
+	repo, err := loadManifests(sources)
+	if err != nil {
+		return nil, err
+	}
+
+	var chained graph.Graph
+	switch chain {
+	case WeakChain:
+		chained = repo.dependencies.FilterEdges([]int{graph.Weak})
+	case StrongChain:
+		chained = repo.schedule
+	default:
+		chained = repo.dependencies
+	}
+
+	if len(components) < 1 {
+		components = repo.components
+	}
+
+	known := make(map[string]bool, len(repo.components))
+	for _, c := range repo.components {
+		known[c] = true
+	}
+	for _, c := range components {
+		if !known[c] {
+			return nil, fmt.Errorf("cannot hash unknown component '%s'", c)
+		}
+	}
+
+	// only the components and their dependencies need hashing
+	chained = chained.Subgraph(append(chained.Descendants(components), components...))
+
+	hs, err := hashes(chained, files, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(Fingerprints, len(components))
+	for _, c := range components {
+		result[c] = hs[c]
+	}
+
+	return result, nil
+}
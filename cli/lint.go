@@ -0,0 +1,170 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/charypar/monobuild/graph"
+	"github.com/charypar/monobuild/manifests"
+	"github.com/charypar/monobuild/set"
+)
+
+// Problem is an issue found in the dependency manifests
+type Problem struct {
+	Component  string
+	Location   string // location of the offending manifest line, if known
+	Message    string
+	Suggestion bool // suggestions are improvements rather than errors
+}
+
+func (p Problem) String() string {
+	message := p.Message
+	if p.Suggestion {
+		message = "suggestion: " + message
+	}
+
+	if p.Location == "" {
+		return fmt.Sprintf("%s: %s", p.Component, message)
+	}
+
+	return fmt.Sprintf("%s: %s: %s", p.Location, p.Component, message)
+}
+
+func locateDependencies(sources Manifests) (manifests.Locations, error) {
+	if len(sources.RepoManifest) > 0 {
+		return manifests.LocateInRepoManifest(sources.RepoManifestFile, sources.RepoManifest), nil
+	}
+
+	files, err := manifestFiles(sources)
+	if err != nil {
+		return nil, err
+	}
+
+	locations, errs := manifests.Locate(files)
+	if errs != nil {
+		return nil, joinErrors("cannot locate dependencies:", errs)
+	}
+
+	return locations, nil
+}
+
+func sortProblems(problems []Problem) {
+	sort.SliceStable(problems, func(i, j int) bool {
+		if problems[i].Component != problems[j].Component {
+			return problems[i].Component < problems[j].Component
+		}
+
+		return problems[i].Location < problems[j].Location
+	})
+}
+
+// redundantDependencies suggests removing declared dependencies which are
+// implied by other dependencies
+func redundantDependencies(declared graph.Graph, locations manifests.Locations) []Problem {
+	problems := []Problem{}
+	strong := declared.FilterEdges([]int{graph.Strong})
+
+	for component, edges := range declared.RedundantEdges() {
+		for _, e := range edges {
+			paths := declared
+			if e.Colour == graph.Strong {
+				paths = strong
+			}
+
+			// find the dependency which implies the redundant one
+			via := ""
+			for _, child := range paths.Children([]string{component}) {
+				if child == e.Label {
+					continue
+				}
+
+				if set.New(paths.Descendants([]string{child})).Has(e.Label) {
+					via = child
+					break
+				}
+			}
+
+			problem := Problem{
+				Component:  component,
+				Message:    fmt.Sprintf("dependency '%s' is redundant, it is implied by '%s'", e.Label, via),
+				Suggestion: true,
+			}
+			if location, ok := locations.Find(component, e.Label); ok {
+				problem.Location = location.String()
+			}
+
+			problems = append(problems, problem)
+		}
+	}
+
+	return problems
+}
+
+// Lint is 'monobuild lint'
+// It compares the declared dependencies of components containing Go code with
+// their Go imports and reports imported components which aren't declared as
+// dependencies, and declared weak dependencies on Go components which are never
+// imported. Strong dependencies express build ordering rather than use of code
+// and are not reported.
+// It also suggests removing dependencies implied by other dependencies.
+func Lint(sources Manifests) ([]Problem, error) {
+	components, declared, errs := readDeclared(sources)
+	if errs != nil {
+		return nil, joinErrors("cannot load dependencies:", errs)
+	}
+
+	goComponents, imports, err := readGoImports(sources, manifests.Names(components))
+	if err != nil {
+		return nil, err
+	}
+
+	locations, err := locateDependencies(sources)
+	if err != nil {
+		return nil, err
+	}
+
+	declaredGraph := declared.AsGraph()
+	importedGraph := imports.AsGraph()
+	strongGraph := declaredGraph.FilterEdges([]int{graph.Strong})
+	isGo := set.New(goComponents)
+
+	problems := []Problem{}
+
+	for _, component := range goComponents {
+		declaredDeps := declaredGraph.Children([]string{component})
+		strong := set.New(strongGraph.Children([]string{component}))
+		importedDeps := importedGraph.Children([]string{component})
+
+		isDeclared := set.New(declaredDeps)
+		for _, dep := range importedDeps {
+			if !isDeclared.Has(dep) {
+				problems = append(problems, Problem{
+					Component: component,
+					Message:   fmt.Sprintf("imports '%s' which is not a declared dependency", dep),
+				})
+			}
+		}
+
+		imported := set.New(importedDeps)
+		for _, dep := range declaredDeps {
+			if imported.Has(dep) || strong.Has(dep) || !isGo.Has(dep) {
+				continue
+			}
+
+			problem := Problem{
+				Component: component,
+				Message:   fmt.Sprintf("declares dependency '%s' which is never imported", dep),
+			}
+			if location, ok := locations.Find(component, dep); ok {
+				problem.Location = location.String()
+			}
+
+			problems = append(problems, problem)
+		}
+	}
+
+	problems = append(problems, redundantDependencies(declaredGraph, locations)...)
+	sortProblems(problems)
+
+	return problems, nil
+}
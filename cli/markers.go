@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/charypar/monobuild/diff"
+	"github.com/charypar/monobuild/manifests"
+	"github.com/charypar/monobuild/set"
+)
+
+// sinceLastSuccess finds the changed files and components, and the components
+// impacted by changes since their last successful build. A component is
+// impacted if its files or the files of any of its dependencies changed since
+// its last successful build, or if it never built successfully.
+func sinceLastSuccess(repo repository, markers diff.Markers) ([]string, []string, []string, error) {
+	changesSince := map[string][]string{}
+	allChanges := set.New([]string{})
+	changed := []string{}
+	impacted := []string{}
+
+	for _, c := range repo.components {
+		commit, ok, err := markers.Get(c)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		// the commit may be gone from the repository, e.g. after a force push
+		if ok {
+			commit, err = diff.ResolveCommit(commit)
+			ok = err == nil
+		}
+
+		if !ok {
+			changed = append(changed, c)
+			impacted = append(impacted, c)
+			continue
+		}
+
+		changes, seen := changesSince[commit]
+		if !seen {
+			changes, err = diff.ChangedFiles(diff.Mode{Mode: diff.Main, BaseCommits: []string{commit}})
+			if err != nil {
+				return nil, nil, nil, err
+			}
+
+			changesSince[commit] = changes
+			allChanges.Union(set.New(changes))
+		}
+
+		if len(manifests.FilterComponents([]string{c}, changes)) > 0 {
+			changed = append(changed, c)
+		}
+
+		closure := append(repo.dependencies.Descendants([]string{c}), c)
+		if len(manifests.FilterComponents(closure, changes)) > 0 {
+			impacted = append(impacted, c)
+		}
+	}
+
+	allChanges.Remove("")
+	changes := allChanges.AsStrings()
+	sort.Strings(changes)
+
+	return changes, changed, impacted, nil
+}
+
+// Mark is 'monobuild mark'
+// It records a commit as the last successful build of components.
+func Mark(sources Manifests, components []string, revision string, markers diff.Markers) error {
+	repo, err := loadManifests(sources)
+	if err != nil {
+		return err
+	}
+
+	// don't mark any components unless all of them are known
+	known := set.New(repo.components)
+	for _, c := range components {
+		if !known.Has(c) {
+			return fmt.Errorf("cannot mark unknown component '%s'", c)
+		}
+	}
+
+	commit, err := diff.ResolveCommit(revision)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range components {
+		if err := markers.Set(c, commit); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
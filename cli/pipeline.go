@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/charypar/monobuild/diff"
+	"github.com/charypar/monobuild/pipeline"
+)
+
+// Target is a CI system to generate a pipeline for
+type Target int
+
+// GitHubMatrix is a GitHub Actions job matrix in JSON
+var GitHubMatrix Target = 1
+
+// GitHubWorkflow is a GitHub Actions workflow in YAML
+var GitHubWorkflow Target = 2
+
+// GitLabCI is a GitLab CI child pipeline in YAML
+var GitLabCI Target = 3
+
+// Buildkite is a Buildkite pipeline in YAML
+var Buildkite Target = 4
+
+// Ninja is a Ninja build file
+var Ninja Target = 5
+
+// Pipeline is 'monobuild pipeline'
+// It generates CI configuration building the selected components in the
+// order of the build schedule.
+func Pipeline(result Result, target Target, opts pipeline.Options) (string, error) {
+	p, err := pipeline.New(result.Dependencies, result.Schedule, result.Selection, result.Components, opts)
+	if err != nil {
+		return "", err
+	}
+
+	switch target {
+	case GitHubMatrix:
+		return pipeline.GitHubMatrix(p)
+	case GitHubWorkflow:
+		return pipeline.GitHubWorkflow(p)
+	case GitLabCI:
+		return pipeline.GitLabCI(p)
+	case Buildkite:
+		return pipeline.Buildkite(p)
+	case Ninja:
+		if err := addFiles(p.Jobs); err != nil {
+			return "", err
+		}
+
+		return pipeline.Ninja(p)
+	}
+
+	return "", fmt.Errorf("unknown pipeline target %d", target)
+}
+
+// PipelineTemplate is 'monobuild pipeline --target template'
+// It renders the pipeline building the selected components with a template.
+func PipelineTemplate(result Result, source string, opts pipeline.Options) (string, error) {
+	p, err := pipeline.New(result.Dependencies, result.Schedule, result.Selection, result.Components, opts)
+	if err != nil {
+		return "", err
+	}
+
+	return pipeline.Template(p, source, result.Changed, result.ChangedFiles)
+}
+
+// addFiles adds the files of each component tracked by git, which exist in the
+// working tree, to its job
+func addFiles(jobs []pipeline.Job) error {
+	tracked, err := diff.TrackedFiles()
+	if err != nil {
+		return err
+	}
+
+	files := make(map[string][]string, len(jobs))
+	for _, j := range jobs {
+		files[j.Component] = []string{}
+	}
+
+	for _, f := range tracked {
+		// components are directories, a file belongs to all its ancestors
+		owners := []string{}
+		for dir := path.Dir(f); dir != "." && dir != "/"; dir = path.Dir(dir) {
+			if _, ok := files[dir]; ok {
+				owners = append(owners, dir)
+			}
+		}
+		if len(owners) < 1 {
+			continue
+		}
+
+		info, err := os.Stat(f)
+		if os.IsNotExist(err) || (err == nil && info.IsDir()) {
+			continue // deleted but not yet staged, or a submodule
+		}
+
+		for _, o := range owners {
+			files[o] = append(files[o], f)
+		}
+	}
+
+	for i, j := range jobs {
+		jobs[i].Files = files[j.Component]
+	}
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charypar/monobuild/config"
+	"github.com/charypar/monobuild/graph"
+	"github.com/charypar/monobuild/set"
+)
+
+// Estimate holds the estimated cost of a build schedule
+type Estimate struct {
+	Durations        map[string]time.Duration // Estimated duration of each scheduled component
+	CriticalPath     []string                 // The longest chain of builds, in build order
+	CriticalDuration time.Duration            // Duration of the critical path
+	TotalDuration    time.Duration            // Duration of all the builds run one by one
+	Workers          int                      // Number of parallel workers, unlimited if less than 1
+	WallClock        time.Duration            // Estimated duration of the builds run by the workers
+}
+
+func (e Estimate) String() string {
+	steps := make([]string, 0, len(e.CriticalPath))
+	for _, c := range e.CriticalPath {
+		steps = append(steps, fmt.Sprintf("%s (%s)", c, e.Durations[c]))
+	}
+
+	workers := "unlimited"
+	if e.Workers > 0 {
+		workers = fmt.Sprint(e.Workers)
+	}
+
+	result := fmt.Sprintf("critical path: %s\n", strings.Join(steps, " -> "))
+	result += fmt.Sprintf("critical path duration: %s\n", e.CriticalDuration)
+	result += fmt.Sprintf("total duration: %s\n", e.TotalDuration)
+	result += fmt.Sprintf("workers: %s\n", workers)
+	result += fmt.Sprintf("estimated wall-clock time: %s\n", e.WallClock)
+
+	return result
+}
+
+// durations estimates the build duration of each component, preferring
+// recorded timings to the configuration
+func durations(components []string, conf config.Config, timings map[string]time.Duration) map[string]time.Duration {
+	result := make(map[string]time.Duration, len(components))
+
+	for _, c := range components {
+		if d, ok := timings[c]; ok {
+			result[c] = d
+			continue
+		}
+
+		result[c] = conf.DurationOf(c)
+	}
+
+	return result
+}
+
+// Plan is 'monobuild plan'
+// It estimates the critical path of the build schedule of the selected
+// components and the wall-clock time to run it with a number of workers.
+func Plan(schedule graph.Graph, selection []string, conf config.Config, timings map[string]time.Duration, workers int) (Estimate, error) {
+	selected := schedule.Subgraph(selection)
+	estimates := durations(selected.Vertices(), conf, timings)
+
+	costs := make(map[string]float64, len(estimates))
+	total := time.Duration(0)
+	for c, d := range estimates {
+		costs[c] = float64(d)
+		total += d
+	}
+
+	path, critical, err := selected.CriticalPath(costs)
+	if err != nil {
+		return Estimate{}, fmt.Errorf("cannot find critical path: %s", err)
+	}
+
+	wallClock, err := selected.Makespan(costs, workers)
+	if err != nil {
+		return Estimate{}, fmt.Errorf("cannot estimate wall-clock time: %s", err)
+	}
+
+	return Estimate{
+		Durations:        estimates,
+		CriticalPath:     path,
+		CriticalDuration: time.Duration(critical),
+		TotalDuration:    total,
+		Workers:          workers,
+		WallClock:        time.Duration(wallClock),
+	}, nil
+}
+
+// Timing is 'monobuild timing'
+// It records the build duration of a component in the timings file.
+func Timing(sources Manifests, component string, duration time.Duration, path string) error {
+	repo, err := loadManifests(sources)
+	if err != nil {
+		return err
+	}
+
+	if !set.New(repo.components).Has(component) {
+		return fmt.Errorf("cannot record timing of unknown component '%s'", component)
+	}
+
+	return config.RecordTiming(path, component, duration)
+}
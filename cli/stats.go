@@ -0,0 +1,145 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/charypar/monobuild/graph"
+)
+
+// ComponentStats holds metrics of a single component
+type ComponentStats struct {
+	Component   string  `json:"component"`
+	WeakIn      int     `json:"weakIn"`      // Number of components weakly depending on the component
+	WeakOut     int     `json:"weakOut"`     // Number of weak dependencies of the component
+	StrongIn    int     `json:"strongIn"`    // Number of components strongly depending on the component
+	StrongOut   int     `json:"strongOut"`   // Number of strong dependencies of the component
+	Dependents  int     `json:"dependents"`  // Number of components depending on the component, directly or transitively
+	Depth       int     `json:"depth"`       // Number of builds which have to run one after another before the component's build can start
+	Betweenness float64 `json:"betweenness"` // Number of shortest dependency paths passing through the component
+}
+
+// RepoStats holds metrics aggregated over all the selected components
+type RepoStats struct {
+	Components         int     `json:"components"`
+	WeakDependencies   int     `json:"weakDependencies"`
+	StrongDependencies int     `json:"strongDependencies"`
+	MaxDepth           int     `json:"maxDepth"`
+	MeanDependents     float64 `json:"meanDependents"`
+	MostDependedOn     string  `json:"mostDependedOn"` // Component with the most dependents
+	MostCentral        string  `json:"mostCentral"`    // Component with the highest betweenness
+}
+
+// Statistics holds metrics of the dependency graph
+type Statistics struct {
+	Components []ComponentStats `json:"components"`
+	Summary    RepoStats        `json:"summary"`
+}
+
+// Table formats the statistics as a table with a summary
+func (s Statistics) Table() string {
+	var b strings.Builder
+
+	w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "COMPONENT\tWEAK IN\tWEAK OUT\tSTRONG IN\tSTRONG OUT\tDEPENDENTS\tDEPTH\tBETWEENNESS")
+	for _, c := range s.Components {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%d\t%d\t%.2f\n", c.Component, c.WeakIn, c.WeakOut, c.StrongIn, c.StrongOut, c.Dependents, c.Depth, c.Betweenness)
+	}
+	w.Flush()
+
+	fmt.Fprintf(&b, "\ncomponents: %d\n", s.Summary.Components)
+	fmt.Fprintf(&b, "weak dependencies: %d\n", s.Summary.WeakDependencies)
+	fmt.Fprintf(&b, "strong dependencies: %d\n", s.Summary.StrongDependencies)
+	fmt.Fprintf(&b, "max depth: %d\n", s.Summary.MaxDepth)
+	fmt.Fprintf(&b, "mean dependents: %.2f\n", s.Summary.MeanDependents)
+	fmt.Fprintf(&b, "most depended on: %s\n", s.Summary.MostDependedOn)
+	fmt.Fprintf(&b, "most central: %s\n", s.Summary.MostCentral)
+
+	return b.String()
+}
+
+// JSON formats the statistics as JSON
+func (s Statistics) JSON() (string, error) {
+	bytes, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("cannot format statistics: %s", err)
+	}
+
+	return string(bytes) + "\n", nil
+}
+
+// degrees counts the edges leading to and from each vertex
+func degrees(g graph.Graph) (map[string]int, map[string]int) {
+	in := make(map[string]int)
+	out := make(map[string]int)
+	reverse := g.Reverse()
+
+	for _, v := range g.Vertices() {
+		out[v] = len(g.Children([]string{v}))
+		in[v] = len(reverse.Children([]string{v}))
+	}
+
+	return in, out
+}
+
+// Stats is 'monobuild stats'
+// It computes the metrics on the whole graph and reports them for the selected
+// components, the summary is aggregated over the selection.
+func Stats(dependencies graph.Graph, schedule graph.Graph, selection []string) (Statistics, error) {
+	depths, err := schedule.Levels()
+	if err != nil {
+		return Statistics{}, fmt.Errorf("cannot find build depth: %s", err)
+	}
+
+	weakIn, weakOut := degrees(dependencies.FilterEdges([]int{graph.Weak}))
+	strongIn, strongOut := degrees(dependencies.FilterEdges([]int{graph.Strong}))
+	betweenness := dependencies.Betweenness()
+	dependents := dependencies.Reverse()
+
+	components := append([]string{}, selection...)
+	sort.Strings(components)
+
+	result := Statistics{Components: make([]ComponentStats, 0, len(components))}
+	summary := &result.Summary
+	mostDependents, mostBetweenness := -1, -1.0
+
+	for _, c := range components {
+		stats := ComponentStats{
+			Component:   c,
+			WeakIn:      weakIn[c],
+			WeakOut:     weakOut[c],
+			StrongIn:    strongIn[c],
+			StrongOut:   strongOut[c],
+			Dependents:  len(dependents.Descendants([]string{c})),
+			Depth:       depths[c],
+			Betweenness: betweenness[c],
+		}
+		result.Components = append(result.Components, stats)
+
+		summary.Components++
+		summary.WeakDependencies += stats.WeakOut
+		summary.StrongDependencies += stats.StrongOut
+		summary.MeanDependents += float64(stats.Dependents)
+
+		if stats.Depth > summary.MaxDepth {
+			summary.MaxDepth = stats.Depth
+		}
+		if stats.Dependents > mostDependents {
+			mostDependents = stats.Dependents
+			summary.MostDependedOn = c
+		}
+		if stats.Betweenness > mostBetweenness {
+			mostBetweenness = stats.Betweenness
+			summary.MostCentral = c
+		}
+	}
+
+	if summary.Components > 0 {
+		summary.MeanDependents /= float64(summary.Components)
+	}
+
+	return result, nil
+}
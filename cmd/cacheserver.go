@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/charypar/monobuild/cache"
+	"github.com/spf13/cobra"
+)
+
+type cacheServerOptions struct {
+	listen string
+	dir    string
+}
+
+var cacheServerOpts cacheServerOptions
+
+var cacheServerCmd = &cobra.Command{
+	Use:   "cache-server",
+	Short: "Serve a build cache over HTTP",
+	Long: `Serve a build cache in a local directory over HTTP, to share records of 
+successful builds between machines using --cache http://<host>:<port>.
+
+The protocol is a GET request to /<key> to read the value stored under a key
+(404 Not Found if there is none) and a PUT request to /<key> to store the
+body of the request. The server doesn't authenticate requests, it's meant for
+tests and small setups on a trusted network.`,
+	Args: cobra.NoArgs,
+	Run:  cacheServerFn,
+}
+
+func init() {
+	rootCmd.AddCommand(cacheServerCmd)
+
+	cacheServerCmd.Flags().StringVar(&cacheServerOpts.listen, "listen", "localhost:8080", "Address to listen on")
+	cacheServerCmd.Flags().StringVar(&cacheServerOpts.dir, "cache-dir", ".monobuild/cache", "Directory to store the cache in")
+}
+
+func cacheServerFn(cmd *cobra.Command, args []string) {
+	handler := cache.Server(cache.Local{Dir: cacheServerOpts.dir})
+
+	fmt.Fprintf(os.Stderr, "Serving cache in %s on %s\n", cacheServerOpts.dir, cacheServerOpts.listen)
+	log.Fatal(http.ListenAndServe(cacheServerOpts.listen, handler))
+}
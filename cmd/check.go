@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/charypar/monobuild/cli"
+	"github.com/charypar/monobuild/rules"
+	"github.com/spf13/cobra"
+)
+
+type checkOptions struct {
+	rulesFile string
+}
+
+var checkOpts checkOptions
+
+var checkCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Check dependencies against architectural rules",
+	Long: `Check dependencies between components against rules read from a rules file,
+with a rule per line in the format
+
+<deny|only> <components> -> <dependencies>
+
+Both sides are comma separated lists of glob patterns (e.g. 'libs/**') or tag
+expressions prefixed with 'tag:' (e.g. 'tag:team:payments+service').
+Dependencies prefixed with '!' only match strong dependencies.
+
+A 'deny' rule forbids the components to depend on any of the dependencies, an
+'only' rule forbids them to depend on anything else, for example
+
+deny libs/** -> apps/**
+deny ** -> !stack*
+only tag:team:a -> libs/**, tag:team:a
+
+Check lists dependencies breaking the rules with the location of their 
+declaration and exits with a non-zero status if any are found.`,
+	Run: checkFn,
+}
+
+func init() {
+	rootCmd.AddCommand(checkCmd)
+
+	checkCmd.Flags().StringVar(&checkOpts.rulesFile, "rules", ".monobuild-rules", "File with the architectural rules")
+}
+
+func checkFn(cmd *cobra.Command, args []string) {
+	constraints, err := rules.Read(checkOpts.rulesFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	problems, err := cli.Check(manifestSources(), constraints)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for _, p := range problems {
+		fmt.Println(p)
+	}
+
+	if len(problems) > 0 {
+		os.Exit(1)
+	}
+}
@@ -4,20 +4,26 @@ import (
 	"bufio"
 	"errors"
 	"fmt"
-	"io/ioutil"
 	"log"
 	"os"
+	"strings"
 
 	"github.com/charypar/monobuild/cli"
+	"github.com/charypar/monobuild/diff"
 	"github.com/spf13/cobra"
 )
 
 type diffOptions struct {
-	baseBranch    string
-	baseCommit    string
+	baseBranches  []string
+	baseCommits   []string
+	combine       string
 	mainBranch    bool
+	mergeQueue    bool
 	rebuildStrong bool
+	sinceSuccess  bool
+	highlight     bool
 	dotHighlight  bool
+	clusters      bool
 }
 
 var diffOpts diffOptions
@@ -36,38 +42,75 @@ the original dependeny graph (using all dependencies).
 
 By default changed files are determined from the local git repository. 
 Optionally, they can be provided externaly from stdin, by adding a hypen (-) after
-the diff command.`,
-	Args: func(cmd *cobra.Command, args []string) error {
-		if len(args) > 1 {
-			return errors.New("Too many arguments")
-		}
-		if len(args) == 1 && args[0] != "-" {
-			return fmt.Errorf("Invalid first argument: %s, only \"-\" is allowed", args[0])
-		}
-
-		return nil
-	},
-	Run: diffFn,
+the diff command.
+
+Changes can be determined against several base branches or commits, by 
+repeating --base-branch or --base-commit, and combined as a union (files 
+changed against any base) or an intersection (files changed against all of 
+them). In merge queue mode, the bases are all the parents of the HEAD merge
+commit, so --base-branch and --base-commit cannot be used. Only one of 
+--main-branch, --merge-queue, --since-last-success and changed files from
+stdin can be used at a time.
+
+With --cached, components with a successful build recorded in the cache for
+their current content (see the record command) are left out.`,
+	Args: diffArgs,
+	Run:  diffFn,
 }
 
-func init() {
-	rootCmd.AddCommand(diffCmd)
+func diffArgs(cmd *cobra.Command, args []string) error {
+	if len(args) > 1 {
+		return errors.New("Too many arguments")
+	}
+	if len(args) == 1 && args[0] != "-" {
+		return fmt.Errorf("Invalid first argument: %s, only \"-\" is allowed", args[0])
+	}
 
-	diffCmd.Flags().StringVar(&diffOpts.baseBranch, "base-branch", "master", "Base branch to use for comparison")
-	diffCmd.Flags().StringVar(&diffOpts.baseCommit, "base-commit", "HEAD^1", "Base commit to compare with (useful in main-brahnch mode when using rebase merging)")
-	diffCmd.Flags().BoolVar(&diffOpts.mainBranch, "main-branch", false, "Run in main branch mode (i.e. only compare with parent commit)")
-	diffCmd.Flags().BoolVar(&diffOpts.rebuildStrong, "rebuild-strong", false, "Include all strong dependencies of affected components")
-	diffCmd.Flags().BoolVar(&commonOpts.printDependencies, "dependencies", false, "Ouput the dependencies, not the build schedule")
-	diffCmd.Flags().BoolVar(&commonOpts.dotFormat, "dot", false, "Print in DOT format for GraphViz")
-	diffCmd.Flags().BoolVar(&commonOpts.printFull, "full", false, "Print the full dependency graph including strengths")
+	return nil
 }
 
-func diffFn(cmd *cobra.Command, args []string) {
-	// first we tediously process the CLI flags
+// addDiffFlags registers the flags controlling change detection on a command
+func addDiffFlags(cmd *cobra.Command) {
+	cmd.Flags().StringArrayVar(&diffOpts.baseBranches, "base-branch", []string{"master"}, "Base branch to use for comparison (can be repeated)")
+	cmd.Flags().StringArrayVar(&diffOpts.baseCommits, "base-commit", []string{"HEAD^1"}, "Base commit to compare with (useful in main-brahnch mode when using rebase merging, can be repeated)")
+	cmd.Flags().StringVar(&diffOpts.combine, "combine", "union", "Combine changes against several bases: 'union' (changed against any) or 'intersection' (changed against all)")
+	cmd.Flags().BoolVar(&diffOpts.mainBranch, "main-branch", false, "Run in main branch mode (i.e. only compare with parent commit)")
+	cmd.Flags().BoolVar(&diffOpts.mergeQueue, "merge-queue", false, "Run in merge queue mode (i.e. compare a merge commit with all its parents)")
+	cmd.Flags().BoolVar(&diffOpts.rebuildStrong, "rebuild-strong", false, "Include all strong dependencies of affected components")
+	cmd.Flags().BoolVar(&diffOpts.sinceSuccess, "since-last-success", false, "Compare each component with its last successful build (see the mark command)")
+	addMarkersFlag(cmd)
+	addCacheFlags(cmd)
+}
+
+// diffContextFrom collects the diff context from the CLI flags and arguments
+func diffContextFrom(cmd *cobra.Command, args []string) cli.DiffContext {
 	var branchMode cli.DiffMode
 	changedFiles := []string{}
+	stdin := len(args) > 0 && args[0] == "-"
+
+	modes := []string{}
+	for _, m := range []struct {
+		name string
+		set  bool
+	}{
+		{"changed files from stdin (-)", stdin},
+		{"--since-last-success", diffOpts.sinceSuccess},
+		{"--merge-queue", diffOpts.mergeQueue},
+		{"--main-branch", diffOpts.mainBranch},
+	} {
+		if m.set {
+			modes = append(modes, m.name)
+		}
+	}
+	if len(modes) > 1 {
+		log.Fatalf("Conflicting modes: %s, only one can be used", strings.Join(modes, ", "))
+	}
 
-	if len(args) > 0 && args[0] == "-" {
+	if diffOpts.mergeQueue && (cmd.Flags().Changed("base-branch") || cmd.Flags().Changed("base-commit")) {
+		log.Fatal("--base-branch and --base-commit cannot be used with --merge-queue, the bases are the parents of the merge commit")
+	}
+
+	if stdin {
 		branchMode = cli.Direct
 
 		// Read stdin into []string
@@ -76,26 +119,70 @@ func diffFn(cmd *cobra.Command, args []string) {
 			changedFiles = append(changedFiles, scanner.Text())
 		}
 
+	} else if diffOpts.sinceSuccess {
+		branchMode = cli.SinceLastSuccess
+	} else if diffOpts.mergeQueue {
+		branchMode = cli.MergeQueue
 	} else if diffOpts.mainBranch {
 		branchMode = cli.MainBranch
 	} else {
 		branchMode = cli.FeatureBranch
 	}
 
-	var format cli.OutputFormat
-	if commonOpts.dotFormat {
-		format = cli.Dot
-	} else {
-		format = cli.Text
+	if diffOpts.combine != "union" && diffOpts.combine != "intersection" {
+		log.Fatalf("Invalid combination: %s, only \"union\" or \"intersection\" are allowed", diffOpts.combine)
 	}
 
-	diffContext := cli.DiffContext{
+	return cli.DiffContext{
 		Mode:         branchMode,
-		BaseBranch:   diffOpts.baseBranch,
-		BaseCommit:   diffOpts.baseCommit,
+		BaseBranches: diffOpts.baseBranches,
+		BaseCommits:  diffOpts.baseCommits,
+		Intersect:    diffOpts.combine == "intersection",
 		ChangedFiles: changedFiles,
+		Markers:      diff.OpenMarkers(markersLocation),
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+
+	addDiffFlags(diffCmd)
+	diffCmd.Flags().BoolVar(&commonOpts.printDependencies, "dependencies", false, "Ouput the dependencies, not the build schedule")
+	diffCmd.Flags().BoolVar(&commonOpts.dotFormat, "dot", false, "Print in DOT format for GraphViz (same as --format dot)")
+	diffCmd.Flags().StringVar(&commonOpts.format, "format", "text", "Output format: 'text', 'dot', 'mermaid', 'plantuml', 'html' or a pipeline target of the pipeline command")
+	diffCmd.Flags().BoolVar(&commonOpts.printFull, "full", false, "Print the full dependency graph including strengths")
+	diffCmd.Flags().BoolVar(&commonOpts.reduce, "reduce", false, "Leave out dependencies implied by other dependencies")
+	diffCmd.Flags().BoolVar(&diffOpts.highlight, "highlight", false, "Print the whole graph, highlighting changed and affected components (not in text format)")
+	diffCmd.Flags().BoolVar(&diffOpts.dotHighlight, "dot-highlight", false, "Print the whole graph in DOT format, highlighting changed and affected components (same as --format dot --highlight)")
+	diffCmd.Flags().BoolVar(&diffOpts.clusters, "clusters", false, "Group components by directory when highlighting")
+	diffCmd.Flags().BoolVar(&diffOpts.clusters, "dot-clusters", false, "Group components by directory when highlighting")
+	diffCmd.Flags().MarkDeprecated("dot-clusters", "use --clusters instead")
+	addPipelineFlags(diffCmd)
+}
+
+func diffFn(cmd *cobra.Command, args []string) {
+	// first we tediously process the CLI flags
+	diffContext := diffContextFrom(cmd, args)
+	scope := selectionScope()
+
+	// pipeline targets are accepted as formats too
+	if isPipelineTarget(commonOpts.format) {
+		sources := manifestSources()
+
+		result, err := cli.Diff(sources, diffContext, scope, diffOpts.rebuildStrong)
+		if err != nil {
+			log.Fatal(err)
+		}
+		result = uncached(result, sources)
+
+		printPipeline(result, commonOpts.format, sources)
+		return
+	}
+
+	format := outputFormat()
+	if diffOpts.dotHighlight {
+		format = cli.Dot
 	}
-	scope := cli.Scope{Scope: commonOpts.scope, TopLevel: commonOpts.topLevel}
 
 	var outType cli.OutputType
 	if commonOpts.printFull {
@@ -106,23 +193,27 @@ func diffFn(cmd *cobra.Command, args []string) {
 		outType = cli.Schedule
 	}
 
-	outputOpts := cli.OutputOptions{Format: format, Type: outType}
+	outputOpts := cli.OutputOptions{
+		Format:    format,
+		Type:      outType,
+		Reduce:    commonOpts.reduce,
+		Highlight: diffOpts.highlight || diffOpts.dotHighlight,
+		Clusters:  diffOpts.clusters,
+	}
 
-	repoManifest := ""
-	if len(commonOpts.repoManifestFile) > 0 {
-		bytes, err := ioutil.ReadFile(commonOpts.repoManifestFile)
-		if err != nil {
-			log.Fatal(err)
-		}
+	// run the CLI command
+	sources := manifestSources()
 
-		repoManifest = string(bytes)
+	result, err := cli.Diff(sources, diffContext, scope, diffOpts.rebuildStrong)
+	if err != nil {
+		log.Fatal(err)
 	}
+	result = uncached(result, sources)
 
-	// run the CLI command
-	dependencies, schedule, impacted, err := cli.Diff(commonOpts.dependencyFilesGlob, diffContext, scope, diffOpts.rebuildStrong, repoManifest)
+	output, err := cli.Format(result, outputOpts)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	fmt.Print(cli.Format(dependencies, schedule, impacted, outputOpts))
+	fmt.Print(output)
 }
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/charypar/monobuild/cli"
+	"github.com/spf13/cobra"
+)
+
+type hashOptions struct {
+	chain    string
+	revision string
+	include  []string
+	ignore   []string
+}
+
+var hashOpts hashOptions
+
+var hashCmd = &cobra.Command{
+	Use:   "hash [component...]",
+	Short: "Print fingerprints of components",
+	Long: `Print a fingerprint of each component (or of all components if none are 
+given), derived from the files in the component's directory tracked by git and
+the fingerprints of its dependencies. The format of each line is:
+
+<component>: <fingerprint>
+
+Fingerprints only depend on the content of the files, so they are the same on
+any branch or machine, and can be used to tag docker images or name artifacts.
+
+The fingerprints of weak and strong dependencies can be chained separately
+with --chain, e.g. to only change the fingerprint of a docker image when the
+code built into it changes. Files counted in fingerprints can be selected with
+include and ignore patterns, relative to the component, in the 'hash' section
+of the repository configuration or with --include and --ignore.`,
+	Run: hashFn,
+}
+
+func init() {
+	rootCmd.AddCommand(hashCmd)
+
+	hashCmd.Flags().StringVar(&hashOpts.chain, "chain", "all", "Dependencies whose fingerprints are chained: 'all', 'weak' or 'strong'")
+	hashCmd.Flags().StringVar(&hashOpts.revision, "revision", "", "Fingerprint the files of a git revision instead of the working tree")
+	hashCmd.Flags().StringArrayVar(&hashOpts.include, "include", []string{}, "Only count files matching a pattern, e.g. '**/*.go' (can be repeated)")
+	hashCmd.Flags().StringArrayVar(&hashOpts.ignore, "ignore", []string{}, "Don't count files matching a pattern, e.g. '**/*.md' (can be repeated)")
+}
+
+func hashFn(cmd *cobra.Command, args []string) {
+	var chain cli.Chain
+	switch hashOpts.chain {
+	case "all":
+		chain = cli.AllChain
+	case "weak":
+		chain = cli.WeakChain
+	case "strong":
+		chain = cli.StrongChain
+	default:
+		log.Fatalf("Invalid chain: %s, only \"all\", \"weak\" or \"strong\" are allowed", hashOpts.chain)
+	}
+
+	sources := manifestSources()
+
+	files := sources.Config.Hash
+	files.Include = append(files.Include, hashOpts.include...)
+	files.Ignore = append(files.Ignore, hashOpts.ignore...)
+
+	fingerprints, err := cli.Hash(sources, args, chain, hashOpts.revision, files)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Print(fingerprints)
+}
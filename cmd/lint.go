@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/charypar/monobuild/cli"
+	"github.com/spf13/cobra"
+)
+
+var lintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Check declared dependencies against Go imports",
+	Long: `Compare dependencies declared in the manifests with the Go import 
+statements of components containing Go code.
+
+Lint reports components importing other components without declaring them
+as dependencies, and declared weak dependencies on Go components which are 
+never imported. Strong dependencies are not reported.
+
+Lint also suggests removing dependencies, which are implied by other 
+dependencies (e.g. a dependency on a library already required by another 
+library).
+
+Lint exits with a non-zero status when problems other than suggestions 
+are found.`,
+	Run: lintFn,
+}
+
+func init() {
+	rootCmd.AddCommand(lintCmd)
+}
+
+func lintFn(cmd *cobra.Command, args []string) {
+	problems, err := cli.Lint(manifestSources())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	failed := false
+	for _, p := range problems {
+		fmt.Println(p)
+		failed = failed || !p.Suggestion
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
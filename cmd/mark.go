@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"errors"
+	"log"
+
+	"github.com/charypar/monobuild/cli"
+	"github.com/charypar/monobuild/diff"
+	"github.com/spf13/cobra"
+)
+
+var markersLocation string
+
+var markCommit string
+
+var markCmd = &cobra.Command{
+	Use:   "mark <component>...",
+	Short: "Record the last successful build of components",
+	Long: `Record a commit (HEAD by default) as the last successful build of components.
+
+With --since-last-success, diff compares each component with its own last
+successful build, instead of one base for all components, so changes of 
+components whose build failed or was skipped are not lost.
+
+The markers are stored as git refs in a namespace (refs/monobuild/success by
+default), which can be shared with 'git push origin "refs/monobuild/*"' and
+'git fetch origin "refs/monobuild/*:refs/monobuild/*"', or in a JSON state 
+file if --markers is a path not starting with refs/.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 1 {
+			return errors.New("At least one component is required")
+		}
+
+		return nil
+	},
+	Run: markFn,
+}
+
+func init() {
+	rootCmd.AddCommand(markCmd)
+
+	addMarkersFlag(markCmd)
+	markCmd.Flags().StringVar(&markCommit, "commit", "HEAD", "Commit of the successful build")
+}
+
+// addMarkersFlag registers the flag locating the last successful build markers on a command
+func addMarkersFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&markersLocation, "markers", "refs/monobuild/success", "Markers of last successful builds: a git ref namespace or a state file")
+}
+
+func markFn(cmd *cobra.Command, args []string) {
+	err := cli.Mark(manifestSources(), args, markCommit, diff.OpenMarkers(markersLocation))
+	if err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+
+	"github.com/charypar/monobuild/cli"
+	"github.com/charypar/monobuild/pipeline"
+	"github.com/spf13/cobra"
+)
+
+type pipelineOptions struct {
+	target       string
+	name         string
+	buildCommand string
+	templateFile string
+	trigger      []string
+}
+
+var pipelineOpts pipelineOptions
+
+var pipelineCmd = &cobra.Command{
+	Use:   "pipeline [-]",
+	Short: "Generate CI configuration for components affected by git changes",
+	Long: `Generate CI configuration building the components affected by changes, 
+in the order of the build schedule created by diff.
+
+Targets:
+
+github-matrix     a GitHub Actions job matrix (JSON) with a 'component', 'id' 
+                  and 'build' for each affected component
+github-workflow   a GitHub Actions workflow (YAML) with a job for each affected
+                  component, which needs the jobs of its strong dependencies,
+                  triggered by the --trigger events
+gitlab-ci         a GitLab CI child pipeline (YAML) with a job for each affected
+                  component, which needs the jobs of its strong dependencies
+buildkite         a Buildkite pipeline (YAML) with a step for each affected
+                  component, which depends on the steps of its strong 
+                  dependencies and of its weak dependencies, allowing those 
+                  to fail, so weakly dependent steps run in order rather than
+                  in parallel
+ninja             a Ninja build file with a phony target for each affected
+                  component, depending on its tracked files, with order-only
+                  dependencies on the targets of its strong dependencies
+template          the output of a Go text/template read from --template, which
+                  can produce configuration for any CI system (see README)
+
+Jobs run the build command declared in the component manifest, or the
+--build-command, in the component's directory. The script of github-workflow,
+gitlab-ci and buildkite jobs, and the image and runner tags of GitLab CI jobs,
+can be set in the 'pipeline' section of the repository configuration.
+
+Changed files are determined the same way as in diff.`,
+	Args: diffArgs,
+	Run:  pipelineFn,
+}
+
+func init() {
+	rootCmd.AddCommand(pipelineCmd)
+
+	addDiffFlags(pipelineCmd)
+	addPipelineFlags(pipelineCmd)
+	pipelineCmd.Flags().StringVar(&pipelineOpts.target, "target", "github-matrix", "CI configuration to generate: 'github-matrix', 'github-workflow', 'gitlab-ci', 'buildkite', 'ninja' or 'template'")
+}
+
+// addPipelineFlags registers the flags controlling generated pipelines on a command
+func addPipelineFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&pipelineOpts.name, "name", "monobuild", "Name of the generated pipeline")
+	cmd.Flags().StringVar(&pipelineOpts.buildCommand, "build-command", "make build", "Build command of components which don't declare one")
+	cmd.Flags().StringSliceVar(&pipelineOpts.trigger, "trigger", []string{"push"}, "Events triggering the generated GitHub Actions workflow, e.g. 'workflow_call'")
+	cmd.Flags().StringVar(&pipelineOpts.templateFile, "template", "", "Go text/template file rendering the pipeline for the 'template' target")
+}
+
+// pipelineTarget finds the pipeline target with a name
+func pipelineTarget(name string) (cli.Target, bool) {
+	switch name {
+	case "github-matrix":
+		return cli.GitHubMatrix, true
+	case "github-workflow":
+		return cli.GitHubWorkflow, true
+	case "gitlab-ci":
+		return cli.GitLabCI, true
+	case "buildkite":
+		return cli.Buildkite, true
+	case "ninja":
+		return cli.Ninja, true
+	}
+
+	return 0, false
+}
+
+// isPipelineTarget checks whether a name is a pipeline target, including
+// the user-provided template
+func isPipelineTarget(name string) bool {
+	_, ok := pipelineTarget(name)
+
+	return ok || name == "template"
+}
+
+// printPipeline generates the pipeline for the result of diff
+func printPipeline(result cli.Result, name string, sources cli.Manifests) {
+	opts := pipeline.Options{
+		Name:         pipelineOpts.name,
+		BuildCommand: pipelineOpts.buildCommand,
+		Trigger:      pipelineOpts.trigger,
+		Template:     sources.Config.Pipeline,
+	}
+
+	var output string
+	var err error
+
+	if target, ok := pipelineTarget(name); ok {
+		output, err = cli.Pipeline(result, target, opts)
+	} else {
+		var source []byte
+		source, err = ioutil.ReadFile(pipelineOpts.templateFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		output, err = cli.PipelineTemplate(result, string(source), opts)
+	}
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Print(output)
+}
+
+func pipelineFn(cmd *cobra.Command, args []string) {
+	if !isPipelineTarget(pipelineOpts.target) {
+		log.Fatalf("Invalid target: %s, only \"github-matrix\", \"github-workflow\", \"gitlab-ci\", \"buildkite\", \"ninja\" or \"template\" are allowed", pipelineOpts.target)
+	}
+
+	sources := manifestSources()
+
+	result, err := cli.Diff(sources, diffContextFrom(cmd, args), selectionScope(), diffOpts.rebuildStrong)
+	if err != nil {
+		log.Fatal(err)
+	}
+	result = uncached(result, sources)
+
+	printPipeline(result, pipelineOpts.target, sources)
+}
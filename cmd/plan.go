@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/charypar/monobuild/cli"
+	"github.com/charypar/monobuild/config"
+	"github.com/spf13/cobra"
+)
+
+type planOptions struct {
+	timingsFile string
+	workers     int
+}
+
+var planOpts planOptions
+
+var planCmd = &cobra.Command{
+	Use:   "plan [-]",
+	Short: "Estimate the cost of the build schedule for components affected by git changes",
+	Long: `Estimate the critical path of the build schedule created by diff (the longest
+chain of builds which have to run one after another) and the wall-clock time
+needed to run the schedule with a number of parallel workers.
+
+Build durations of components are taken from a timings file recorded by 
+previous runs with the timing command, with a line per build in the format
+
+<component>: <duration>
+
+e.g. 'libs/lib1: 1m30s' (later lines replace earlier ones), or estimated in the
+repository configuration.
+
+Changed files are determined the same way as in diff.`,
+	Args: diffArgs,
+	Run:  planFn,
+}
+
+func init() {
+	rootCmd.AddCommand(planCmd)
+
+	addDiffFlags(planCmd)
+	planCmd.Flags().StringVar(&planOpts.timingsFile, "timings", ".monobuild-timings", "File with build durations recorded by previous runs")
+	planCmd.Flags().IntVar(&planOpts.workers, "workers", 1, "Number of parallel workers (0 for unlimited)")
+}
+
+func planFn(cmd *cobra.Command, args []string) {
+	sources := manifestSources()
+
+	timings, err := config.ReadTimings(planOpts.timingsFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	result, err := cli.Diff(sources, diffContextFrom(cmd, args), selectionScope(), diffOpts.rebuildStrong)
+	if err != nil {
+		log.Fatal(err)
+	}
+	result = uncached(result, sources)
+
+	estimate, err := cli.Plan(result.Schedule, result.Selection, sources.Config, timings, planOpts.workers)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Print(estimate)
+}
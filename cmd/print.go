@@ -2,7 +2,6 @@ package cmd
 
 import (
 	"fmt"
-	"io/ioutil"
 	"log"
 
 	"github.com/charypar/monobuild/cli"
@@ -26,22 +25,35 @@ func init() {
 	rootCmd.AddCommand(printCmd)
 
 	printCmd.Flags().BoolVar(&commonOpts.printDependencies, "dependencies", false, "Ouput the dependencies, not the build schedule")
-	printCmd.Flags().BoolVar(&commonOpts.dotFormat, "dot", false, "Print in DOT format for GraphViz")
+	printCmd.Flags().BoolVar(&commonOpts.dotFormat, "dot", false, "Print in DOT format for GraphViz (same as --format dot)")
+	printCmd.Flags().StringVar(&commonOpts.format, "format", "text", "Output format: 'text', 'dot', 'mermaid', 'plantuml', 'html' or a pipeline target of the pipeline command")
 	printCmd.Flags().BoolVar(&commonOpts.printFull, "full", false, "Print the full dependency graph including strengths")
+	printCmd.Flags().BoolVar(&commonOpts.reduce, "reduce", false, "Leave out dependencies implied by other dependencies")
+	addPipelineFlags(printCmd)
+	addCacheFlags(printCmd)
 
 }
 
 func printFn(cmd *cobra.Command, args []string) {
 	// first we tediously process the CLI flags
 
-	var format cli.OutputFormat
-	if commonOpts.dotFormat {
-		format = cli.Dot
-	} else {
-		format = cli.Text
+	scope := selectionScope()
+
+	// pipeline targets are accepted as formats too
+	if isPipelineTarget(commonOpts.format) {
+		sources := manifestSources()
+
+		result, err := cli.Print(sources, scope)
+		if err != nil {
+			log.Fatal(err)
+		}
+		result = uncached(result, sources)
+
+		printPipeline(result, commonOpts.format, sources)
+		return
 	}
 
-	scope := cli.Scope{Scope: commonOpts.scope, TopLevel: commonOpts.topLevel}
+	format := outputFormat()
 
 	var outType cli.OutputType
 	if commonOpts.printFull {
@@ -52,24 +64,21 @@ func printFn(cmd *cobra.Command, args []string) {
 		outType = cli.Schedule
 	}
 
-	outputOpts := cli.OutputOptions{Format: format, Type: outType}
+	outputOpts := cli.OutputOptions{Format: format, Type: outType, Reduce: commonOpts.reduce}
 
-	repoManifest := ""
-	if len(commonOpts.repoManifestFile) > 0 {
-		bytes, err := ioutil.ReadFile(commonOpts.repoManifestFile)
-		if err != nil {
-			fmt.Printf("Failed reading: %s", err)
-			log.Fatal(err)
-		}
+	// then we run the CLI
+	sources := manifestSources()
 
-		repoManifest = string(bytes)
+	result, err := cli.Print(sources, scope)
+	if err != nil {
+		log.Fatal(err)
 	}
+	result = uncached(result, sources)
 
-	// then we run the CLI
-	dependencies, schedule, impacted, err := cli.Print(commonOpts.dependencyFilesGlob, scope, repoManifest)
+	output, err := cli.Format(result, outputOpts)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	fmt.Print(cli.Format(dependencies, schedule, impacted, outputOpts))
+	fmt.Print(output)
 }
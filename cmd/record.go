@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"errors"
+	"log"
+
+	"github.com/charypar/monobuild/cache"
+	"github.com/charypar/monobuild/cli"
+	"github.com/spf13/cobra"
+)
+
+type cacheOptions struct {
+	cached   bool
+	location string
+}
+
+var cacheOpts cacheOptions
+
+var recordCmd = &cobra.Command{
+	Use:   "record <component>...",
+	Short: "Record successful builds of components in the cache",
+	Long: `Record successful builds of components in the cache, under a content hash 
+of the files in each component's directory (tracked by git) and of the hashes 
+of its dependencies.
+
+Commands taking the --cached flag skip components whose hash has a recorded 
+successful build, so a component is only built again when its files or the
+files of its dependencies change.
+
+The cache can be a local directory, an HTTP cache server (see cache-server)
+or an S3 bucket, e.g. s3://bucket/prefix. The S3 credentials, region and the
+endpoint of an S3-compatible API are read from the AWS_ACCESS_KEY_ID, 
+AWS_SECRET_ACCESS_KEY, AWS_SESSION_TOKEN, AWS_REGION and AWS_ENDPOINT_URL 
+environment variables.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 1 {
+			return errors.New("At least one component is required")
+		}
+
+		return nil
+	},
+	Run: recordFn,
+}
+
+func init() {
+	rootCmd.AddCommand(recordCmd)
+
+	addCacheLocationFlag(recordCmd)
+}
+
+// addCacheFlags registers the flags controlling the build cache on a command
+func addCacheFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&cacheOpts.cached, "cached", false, "Skip components with a successful build recorded in the cache")
+	addCacheLocationFlag(cmd)
+}
+
+// addCacheLocationFlag registers the flag locating the build cache on a command
+func addCacheLocationFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&cacheOpts.location, "cache", ".monobuild/cache", "Build cache: a directory, an HTTP cache server URL or s3://bucket/prefix")
+}
+
+// openCache opens the build cache given by the CLI flags
+func openCache() cache.Cache {
+	c, err := cache.Open(cacheOpts.location)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return c
+}
+
+// uncached skips components with a recorded successful build if requested
+func uncached(result cli.Result, sources cli.Manifests) cli.Result {
+	if !cacheOpts.cached {
+		return result
+	}
+
+	result, err := cli.Uncached(sources, result, openCache())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return result
+}
+
+func recordFn(cmd *cobra.Command, args []string) {
+	err := cli.Record(manifestSources(), args, openCache())
+	if err != nil {
+		log.Fatal(err)
+	}
+}
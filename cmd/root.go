@@ -2,8 +2,12 @@ package cmd
 
 import (
 	"fmt"
+	"io/ioutil"
+	"log"
 	"os"
 
+	"github.com/charypar/monobuild/cli"
+	"github.com/charypar/monobuild/config"
 	"github.com/spf13/cobra"
 )
 
@@ -17,21 +21,117 @@ history.`,
 
 type commonOptions struct {
 	dependencyFilesGlob string
+	componentFilesGlob  string
 	repoManifestFile    string
+	goImports           bool
+	goModulesGlob       string
+	configFile          string
 	scope               string
+	dependentsOf        string
+	depth               int
+	strength            string
 	topLevel            bool
+	tags                []string
+	excludeTags         []string
 	printDependencies   bool
 	dotFormat           bool
+	format              string
 	printFull           bool
+	reduce              bool
 }
 
 var commonOpts commonOptions
 
 func init() {
 	rootCmd.PersistentFlags().StringVar(&commonOpts.dependencyFilesGlob, "dependency-files", "**/Dependencies", "Search pattern for dependency files")
+	rootCmd.PersistentFlags().StringVar(&commonOpts.componentFilesGlob, "component-files", "**/monobuild.yaml", "Search pattern for structured component manifests")
 	rootCmd.PersistentFlags().StringVarP(&commonOpts.repoManifestFile, "file", "f", "", "Full manifest file (as produced by 'print --full')")
-	rootCmd.PersistentFlags().StringVar(&commonOpts.scope, "scope", "", "Scope output to a single component and its dependencies")
+	rootCmd.PersistentFlags().BoolVar(&commonOpts.goImports, "go-imports", false, "Infer weak dependencies from Go import statements")
+	rootCmd.PersistentFlags().StringVar(&commonOpts.goModulesGlob, "go-modules", "**/go.mod", "Search pattern for go.mod files used to resolve Go imports")
+	rootCmd.PersistentFlags().StringVar(&commonOpts.scope, "scope", "", "Scope output to components matching a selector (e.g. 'app1 | libs/*') and their dependencies")
+	rootCmd.PersistentFlags().StringVar(&commonOpts.dependentsOf, "dependents-of", "", "Only list components matching a selector and everything that depends on them")
+	rootCmd.PersistentFlags().IntVar(&commonOpts.depth, "depth", 0, "Limit --scope and --dependents-of to a number of levels (0 for unlimited)")
+	rootCmd.PersistentFlags().StringVar(&commonOpts.strength, "strength", "", "Limit --dependents-of to 'weak' or 'strong' dependencies")
 	rootCmd.PersistentFlags().BoolVar(&commonOpts.topLevel, "top-level", false, "Only list top-level components that nothing depends on")
+	rootCmd.PersistentFlags().StringArrayVar(&commonOpts.tags, "tag", []string{}, "Only list components with tags, e.g. 'service' or 'service+team:payments' (can be repeated)")
+	rootCmd.PersistentFlags().StringArrayVar(&commonOpts.excludeTags, "exclude-tag", []string{}, "Exclude components with tags, same format as --tag (can be repeated)")
+	rootCmd.PersistentFlags().StringVar(&commonOpts.configFile, "config", ".monobuild.yaml", "Repository configuration file")
+}
+
+// manifestSources collects the sources of the dependency graph from the CLI flags
+func manifestSources() cli.Manifests {
+	repoManifest := ""
+	if len(commonOpts.repoManifestFile) > 0 {
+		bytes, err := ioutil.ReadFile(commonOpts.repoManifestFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		repoManifest = string(bytes)
+	}
+
+	conf, err := config.Read(commonOpts.configFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return cli.Manifests{
+		DependencyFilesGlob: commonOpts.dependencyFilesGlob,
+		ComponentFilesGlob:  commonOpts.componentFilesGlob,
+		RepoManifest:        repoManifest,
+		RepoManifestFile:    commonOpts.repoManifestFile,
+		GoImports:           commonOpts.goImports,
+		GoModulesGlob:       commonOpts.goModulesGlob,
+		Config:              conf,
+	}
+}
+
+// selectionScope collects the scope of selection from the CLI flags
+func selectionScope() cli.Scope {
+	var strength cli.Strength
+	switch commonOpts.strength {
+	case "":
+		strength = cli.AnyStrength
+	case "weak":
+		strength = cli.WeakOnly
+	case "strong":
+		strength = cli.StrongOnly
+	default:
+		log.Fatalf("Invalid strength: %s, only \"weak\" or \"strong\" are allowed", commonOpts.strength)
+	}
+
+	return cli.Scope{
+		Scope:        commonOpts.scope,
+		DependentsOf: commonOpts.dependentsOf,
+		Depth:        commonOpts.depth,
+		Strength:     strength,
+		TopLevel:     commonOpts.topLevel,
+		Tags:         commonOpts.tags,
+		ExcludeTags:  commonOpts.excludeTags,
+	}
+}
+
+// outputFormat collects the output format from the CLI flags
+func outputFormat() cli.OutputFormat {
+	if commonOpts.dotFormat {
+		return cli.Dot
+	}
+
+	switch commonOpts.format {
+	case "text":
+		return cli.Text
+	case "dot":
+		return cli.Dot
+	case "mermaid":
+		return cli.Mermaid
+	case "plantuml":
+		return cli.PlantUML
+	case "html":
+		return cli.HTML
+	}
+
+	log.Fatalf("Invalid format: %s, only \"text\", \"dot\", \"mermaid\", \"plantuml\" or \"html\" are allowed", commonOpts.format)
+	return cli.Text
 }
 
 // Execute the CLI
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/charypar/monobuild/cli"
+	"github.com/spf13/cobra"
+)
+
+type statsOptions struct {
+	json bool
+}
+
+var statsOpts statsOptions
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show metrics of the dependency graph",
+	Long: `Show metrics of every component in the dependency graph and a summary for the
+whole repository.
+
+For each component, stats shows the number of weak and strong dependencies
+leading in and out of it, the number of components depending on it (directly
+or transitively), its depth in the build schedule (how many builds have to run
+one after another before it can be built) and its betweenness (how many
+shortest dependency paths between other components pass through it).
+
+Metrics are computed on the whole graph, scope options only limit which
+components are shown and summarised.`,
+	Run: statsFn,
+}
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+
+	statsCmd.Flags().BoolVar(&statsOpts.json, "json", false, "Output the statistics as JSON")
+}
+
+func statsFn(cmd *cobra.Command, args []string) {
+	result, err := cli.Print(manifestSources(), selectionScope())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	stats, err := cli.Stats(result.Dependencies, result.Schedule, result.Selection)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if !statsOpts.json {
+		fmt.Print(stats.Table())
+		return
+	}
+
+	output, err := stats.JSON()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Print(output)
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/charypar/monobuild/cli"
+	"github.com/spf13/cobra"
+)
+
+var timingCmd = &cobra.Command{
+	Use:   "timing <component> <duration>",
+	Short: "Record the build duration of a component for plan",
+	Long: `Record the build duration of a component (e.g. 1m30s) in the timings file
+read by plan, by appending a line in the format
+
+<component>: <duration>
+
+Later lines replace earlier ones, so plan uses the latest recorded duration.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 2 {
+			return errors.New("A component and a duration are required")
+		}
+
+		return nil
+	},
+	Run: timingFn,
+}
+
+func init() {
+	rootCmd.AddCommand(timingCmd)
+
+	timingCmd.Flags().StringVar(&planOpts.timingsFile, "timings", ".monobuild-timings", "File with build durations recorded by previous runs")
+}
+
+func timingFn(cmd *cobra.Command, args []string) {
+	duration, err := time.ParseDuration(args[1])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := cli.Timing(manifestSources(), args[0], duration, planOpts.timingsFile); err != nil {
+		log.Fatal(err)
+	}
+}
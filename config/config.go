@@ -0,0 +1,172 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bmatcuk/doublestar"
+	"github.com/charypar/monobuild/set"
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds the repository wide configuration of monobuild
+type Config struct {
+	// Tags assigned to components matching a glob pattern
+	Tags map[string][]string `yaml:"tags"`
+	// Estimated build durations of components matching a glob pattern
+	Durations map[string]Duration `yaml:"durations"`
+	// Estimated build duration of components without a better estimate
+	DefaultDuration Duration `yaml:"default_duration"`
+	// Template of jobs in generated CI pipelines
+	Pipeline JobTemplate `yaml:"pipeline"`
+	// Files of components counted in their content hashes
+	Hash HashFiles `yaml:"hash"`
+}
+
+// JobTemplate holds the settings shared by all jobs of a generated CI pipeline
+type JobTemplate struct {
+	Image  string   `yaml:"image"`  // Container image to run the jobs in (GitLab CI)
+	Tags   []string `yaml:"tags"`   // Tags selecting the runners (GitLab CI)
+	Script []string `yaml:"script"` // Commands replacing the build command of components
+}
+
+// HashFiles selects the files counted in content hashes of components with
+// glob patterns, matched against paths relative to the component
+type HashFiles struct {
+	Include []string `yaml:"include"` // Only files matching a pattern count, all files if empty
+	Ignore  []string `yaml:"ignore"`  // Files matching a pattern don't count
+}
+
+// Counts checks whether a file counts in the content hash of its component,
+// given its path relative to the component
+func (h HashFiles) Counts(path string) bool {
+	included := len(h.Include) < 1
+	for _, pattern := range h.Include {
+		if matched, _ := doublestar.Match(pattern, path); matched {
+			included = true
+			break
+		}
+	}
+
+	if !included {
+		return false
+	}
+
+	for _, pattern := range h.Ignore {
+		if matched, _ := doublestar.Match(pattern, path); matched {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Duration is a time.Duration written as a string, e.g. 1m30s
+type Duration time.Duration
+
+// UnmarshalYAML parses the duration from a string
+func (d *Duration) UnmarshalYAML(value *yaml.Node) error {
+	parsed, err := time.ParseDuration(value.Value)
+	if err != nil {
+		return fmt.Errorf("line %d: %s", value.Line, err)
+	}
+
+	*d = Duration(parsed)
+	return nil
+}
+
+// Read reads the configuration at path. A missing configuration file is not
+// an error and results in an empty configuration.
+func Read(path string) (Config, error) {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return Config{}, nil
+	}
+	if err != nil {
+		return Config{}, fmt.Errorf("cannot read configuration %s: %s", path, err)
+	}
+
+	var config Config
+	if err := yaml.Unmarshal(content, &config); err != nil {
+		return Config{}, fmt.Errorf("cannot read configuration %s: %s", path, err)
+	}
+
+	patterns := []string{}
+	for pattern := range config.Tags {
+		patterns = append(patterns, pattern)
+	}
+	for pattern := range config.Durations {
+		patterns = append(patterns, pattern)
+	}
+
+	for _, pattern := range patterns {
+		if _, err := doublestar.Match(pattern, ""); err != nil {
+			return Config{}, fmt.Errorf("bad component pattern '%s' in %s: %s", pattern, path, err)
+		}
+	}
+
+	for _, pattern := range append(config.Hash.Include, config.Hash.Ignore...) {
+		if _, err := doublestar.Match(pattern, ""); err != nil {
+			return Config{}, fmt.Errorf("bad file pattern '%s' in %s: %s", pattern, path, err)
+		}
+	}
+
+	return config, nil
+}
+
+// TagsOf returns the tags assigned to a component by the configuration
+func (c Config) TagsOf(component string) []string {
+	tags := []string{}
+
+	for pattern, ts := range c.Tags {
+		if matched, _ := doublestar.Match(pattern, component); matched {
+			tags = append(tags, ts...)
+		}
+	}
+
+	sort.Strings(tags)
+	return tags
+}
+
+// MatchesTags checks whether tags satisfy a tag expression. An expression is
+// a list of tags joined with '+', all of which need to be present. Tags
+// prefixed with '!' must not be present.
+func MatchesTags(expression string, tags set.Set) bool {
+	for _, tag := range strings.Split(expression, "+") {
+		tag = strings.TrimSpace(tag)
+
+		if strings.HasPrefix(tag, "!") {
+			if tags.Has(tag[1:]) {
+				return false
+			}
+
+			continue
+		}
+
+		if !tags.Has(tag) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// DurationOf returns the estimated build duration of a component. When
+// several patterns match the component, the longest pattern wins.
+func (c Config) DurationOf(component string) time.Duration {
+	duration, best := c.DefaultDuration, ""
+
+	for pattern, d := range c.Durations {
+		matched, _ := doublestar.Match(pattern, component)
+		if matched && (len(pattern) > len(best) || (len(pattern) == len(best) && pattern < best)) {
+			duration, best = d, pattern
+		}
+	}
+
+	return time.Duration(duration)
+}
@@ -0,0 +1,128 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/charypar/monobuild/set"
+)
+
+func TestConfig_TagsOf(t *testing.T) {
+	config := Config{Tags: map[string][]string{
+		"apps/*":    []string{"service"},
+		"libs/**":   []string{"library"},
+		"apps/app1": []string{"team:payments", "lang:go"},
+	}}
+
+	tests := []struct {
+		name      string
+		component string
+		want      []string
+	}{
+		{"returns no tags for unmatched component", "stack1", []string{}},
+		{"returns tags of a matched pattern", "apps/app2", []string{"service"}},
+		{"matches nested components", "libs/go/lib1", []string{"library"}},
+		{"combines tags of all matched patterns", "apps/app1", []string{"lang:go", "service", "team:payments"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := config.TagsOf(tt.component); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Config.TagsOf() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRead(t *testing.T) {
+	got, err := Read("../test/fixtures/component-manifests/.monobuild.yaml")
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+
+	want := Config{
+		Tags:     map[string][]string{"app*": []string{"service", "lang:go"}},
+		Pipeline: JobTemplate{Image: "golang:1.17", Tags: []string{"docker"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Read() = %#v, want %#v", got, want)
+	}
+
+	got, err = Read("../test/fixtures/component-manifests/missing.yaml")
+	if err != nil || !reflect.DeepEqual(got, Config{}) {
+		t.Errorf("Read() of a missing file = %#v, %v, want empty config", got, err)
+	}
+}
+
+func TestConfig_DurationOf(t *testing.T) {
+	config := Config{
+		Durations: map[string]Duration{
+			"apps/*":    Duration(2 * time.Minute),
+			"apps/app1": Duration(5 * time.Minute),
+		},
+		DefaultDuration: Duration(time.Minute),
+	}
+
+	tests := []struct {
+		name      string
+		component string
+		want      time.Duration
+	}{
+		{"returns the default for unmatched component", "libs/lib1", time.Minute},
+		{"returns duration of a matched pattern", "apps/app2", 2 * time.Minute},
+		{"prefers the longest matching pattern", "apps/app1", 5 * time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := config.DurationOf(tt.component); got != tt.want {
+				t.Errorf("Config.DurationOf() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHashFiles_Counts(t *testing.T) {
+	tests := []struct {
+		name  string
+		files HashFiles
+		path  string
+		want  bool
+	}{
+		{"counts all files by default", HashFiles{}, "docs/README.md", true},
+		{"counts included files", HashFiles{Include: []string{"**/*.go", "go.mod"}}, "cmd/main.go", true},
+		{"does not count other files", HashFiles{Include: []string{"**/*.go", "go.mod"}}, "docs/README.md", false},
+		{"does not count ignored files", HashFiles{Ignore: []string{"**/*.md"}}, "README.md", false},
+		{"prefers ignoring to including", HashFiles{Include: []string{"**/*.go"}, Ignore: []string{"**/*_test.go"}}, "main_test.go", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.files.Counts(tt.path); got != tt.want {
+				t.Errorf("HashFiles.Counts() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchesTags(t *testing.T) {
+	tags := set.New([]string{"service", "team:payments"})
+
+	tests := []struct {
+		name       string
+		expression string
+		want       bool
+	}{
+		{"matches a present tag", "service", true},
+		{"does not match a missing tag", "library", false},
+		{"matches when all tags are present", "service+team:payments", true},
+		{"does not match when a tag is missing", "service+lang:go", false},
+		{"matches a negated missing tag", "service+!lang:go", true},
+		{"does not match a negated present tag", "!team:payments", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MatchesTags(tt.expression, tags); got != tt.want {
+				t.Errorf("MatchesTags() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"time"
+)
+
+// ReadTimings reads build durations recorded by previous runs. The file has
+// a line per build in the format
+//
+// <component>: <duration>
+//
+// for example 'libs/lib1: 1m30s'. Later lines replace earlier ones, so new
+// timings can be appended to the file. A missing file results in no timings.
+func ReadTimings(path string) (map[string]time.Duration, error) {
+	timings := make(map[string]time.Duration)
+
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return timings, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot open timings %s: %s", path, err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if len(text) < 1 || text[0] == '#' { // skip blank lines and comments
+			continue
+		}
+
+		separator := strings.LastIndex(text, ":")
+		if separator < 0 {
+			return nil, fmt.Errorf("%s:%d: bad line format: '%s' expected 'component: duration'", path, line, text)
+		}
+
+		duration, err := time.ParseDuration(strings.TrimSpace(text[separator+1:]))
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %s", path, line, err)
+		}
+
+		timings[strings.TrimSpace(text[:separator])] = duration
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("cannot read timings %s: %s", path, err)
+	}
+
+	return timings, nil
+}
+
+// RecordTiming appends the build duration of a component to the timings at
+// path, creating the file if needed
+func RecordTiming(path string, component string, duration time.Duration) error {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("cannot open timings %s: %s", path, err)
+	}
+	defer file.Close()
+
+	if _, err := fmt.Fprintf(file, "%s: %s\n", component, duration); err != nil {
+		return fmt.Errorf("cannot write timings %s: %s", path, err)
+	}
+
+	return nil
+}
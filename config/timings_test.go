@@ -0,0 +1,94 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestReadTimings(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    map[string]time.Duration
+		wantErr bool
+	}{
+		{
+			"reads an empty file",
+			"",
+			map[string]time.Duration{},
+			false,
+		},
+		{
+			"reads timings",
+			"# timings\napp1: 1m30s\n\nlibs/lib1: 20s\n",
+			map[string]time.Duration{"app1": 90 * time.Second, "libs/lib1": 20 * time.Second},
+			false,
+		},
+		{
+			"prefers later lines",
+			"app1: 1m30s\napp1: 2m\n",
+			map[string]time.Duration{"app1": 2 * time.Minute},
+			false,
+		},
+		{
+			"fails on a bad line",
+			"app1 1m30s\n",
+			nil,
+			true,
+		},
+		{
+			"fails on a bad duration",
+			"app1: soon\n",
+			nil,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "timings")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := ReadTimings(path)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ReadTimings() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadTimings() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecordTiming(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "timings")
+
+	records := []struct {
+		component string
+		duration  time.Duration
+	}{
+		{"app1", 90 * time.Second},
+		{"libs/lib1", 20 * time.Second},
+		{"app1", 2 * time.Minute},
+	}
+	for _, r := range records {
+		if err := RecordTiming(path, r.component, r.duration); err != nil {
+			t.Fatalf("RecordTiming() error = %v", err)
+		}
+	}
+
+	got, err := ReadTimings(path)
+	if err != nil {
+		t.Fatalf("ReadTimings() error = %v", err)
+	}
+
+	want := map[string]time.Duration{"app1": 2 * time.Minute, "libs/lib1": 20 * time.Second}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("RecordTiming() recorded %v, want %v", got, want)
+	}
+}
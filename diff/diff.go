@@ -9,7 +9,8 @@ import (
 	"github.com/charypar/monobuild/graph"
 )
 
-// BranchMode is the diff mode based on the kind of branch, either Feature or Main
+// BranchMode is the diff mode based on the kind of branch, either Feature,
+// Main or MergeQueue
 type BranchMode int
 
 // Feature is a feature branch mode
@@ -18,41 +19,81 @@ var Feature BranchMode = 1
 // Main is a main branch mode
 var Main BranchMode = 2
 
+// MergeQueue is a merge queue mode, comparing a merge commit to its parents
+var MergeQueue BranchMode = 3
+
+// Combination is the way changes against several bases are combined
+type Combination int
+
+// Union combines changes to files changed against any of the bases
+var Union Combination = 1
+
+// Intersection combines changes to files changed against all of the bases
+var Intersection Combination = 2
+
 // Mode holds options for the Diff command
 type Mode struct {
-	Mode       BranchMode
-	BaseBranch string
-	BaseCommit string
+	Mode         BranchMode
+	BaseBranches []string
+	BaseCommits  []string
+	Combine      Combination // Union if not set
 }
 
-func diffBase(mode Mode) (string, error) {
-	if mode.Mode == Main {
-		return mode.BaseCommit, nil
-	}
-
-	gitMergeBase := exec.Command("git", "merge-base", mode.BaseBranch, "HEAD")
+func mergeBase(branch string) (string, error) {
+	gitMergeBase := exec.Command("git", "merge-base", branch, "HEAD")
 	mergeBase, err := gitMergeBase.Output()
 	if err != nil {
 		if ee, ok := err.(*exec.ExitError); ok {
-			return "", fmt.Errorf("cannot find merge base with branch '%s': %s", mode.BaseBranch, ee.Stderr)
+			return "", fmt.Errorf("cannot find merge base with branch '%s': %s", branch, ee.Stderr)
 		} else {
-			return "", fmt.Errorf("cannot find merge base with branch '%s': %s", mode.BaseBranch, err)
+			return "", fmt.Errorf("cannot find merge base with branch '%s': %s", branch, err)
 		}
 	}
 
 	return strings.TrimRight(string(mergeBase), "\n"), nil
 }
 
-// ChangedFiles uses git to determine the list of files that changed for
-// the current revision.
-// It can operate in a normal (branch) mode, where it compares to a 'baseBranch'
-// or a 'mainBranch' mode, where it compares to the previous revision or a 'baseCommit'
-func ChangedFiles(mode Mode) ([]string, error) {
-	base, err := diffBase(mode)
+func parents() ([]string, error) {
+	gitRevParse := exec.Command("git", "rev-parse", "HEAD^@")
+	out, err := gitRevParse.Output()
 	if err != nil {
-		return []string{}, err
+		if ee, ok := err.(*exec.ExitError); ok {
+			return nil, fmt.Errorf("cannot find parents of HEAD: %s", ee.Stderr)
+		} else {
+			return nil, fmt.Errorf("cannot find parents of HEAD: %s", err)
+		}
+	}
+
+	result := strings.Fields(string(out))
+	if len(result) < 1 {
+		return nil, fmt.Errorf("cannot find parents of HEAD: it has none")
+	}
+
+	return result, nil
+}
+
+func diffBases(mode Mode) ([]string, error) {
+	switch mode.Mode {
+	case Main:
+		return mode.BaseCommits, nil
+	case MergeQueue:
+		return parents()
 	}
 
+	bases := make([]string, 0, len(mode.BaseBranches))
+	for _, branch := range mode.BaseBranches {
+		base, err := mergeBase(branch)
+		if err != nil {
+			return nil, err
+		}
+
+		bases = append(bases, base)
+	}
+
+	return bases, nil
+}
+
+func changedSince(base string) ([]string, error) {
 	gitDiff := exec.Command("git", "diff", "--no-commit-id", "--name-only", "-r", base)
 
 	gitOut, err := gitDiff.Output()
@@ -64,8 +105,61 @@ func ChangedFiles(mode Mode) ([]string, error) {
 		}
 	}
 
-	changed := strings.Split(strings.TrimRight(string(gitOut), "\n"), "\n")
-	return changed, nil
+	return strings.Split(strings.TrimRight(string(gitOut), "\n"), "\n"), nil
+}
+
+// combine combines the lists of files changed against several bases
+func combine(changes [][]string, combination Combination) []string {
+	counts := map[string]int{}
+	for _, files := range changes {
+		seen := map[string]bool{}
+		for _, f := range files {
+			if f != "" && !seen[f] {
+				counts[f]++
+				seen[f] = true
+			}
+		}
+	}
+
+	result := []string{}
+	for f, count := range counts {
+		if combination != Intersection || count == len(changes) {
+			result = append(result, f)
+		}
+	}
+	sort.Strings(result)
+
+	return result
+}
+
+// ChangedFiles uses git to determine the list of files that changed for
+// the current revision.
+// It can operate in a normal (branch) mode, where it compares to the merge
+// bases with 'baseBranches', a 'mainBranch' mode, where it compares to the
+// previous revision or 'baseCommits', or a 'mergeQueue' mode, where it
+// compares to all the parents of a merge commit. Changes against several
+// bases are combined as a union or an intersection.
+func ChangedFiles(mode Mode) ([]string, error) {
+	bases, err := diffBases(mode)
+	if err != nil {
+		return []string{}, err
+	}
+
+	if len(bases) < 1 {
+		return []string{}, fmt.Errorf("cannot find changed files: no base to compare with")
+	}
+
+	changes := make([][]string, 0, len(bases))
+	for _, base := range bases {
+		changed, err := changedSince(base)
+		if err != nil {
+			return []string{}, err
+		}
+
+		changes = append(changes, changed)
+	}
+
+	return combine(changes, mode.Combine), nil
 }
 
 // Impacted calculates the list of changes impacted by a change
@@ -78,3 +172,54 @@ func Impacted(changedComponents []string, dependencies graph.Graph) []string {
 
 	return result
 }
+
+// TrackedFiles uses git to list the files tracked in the repository
+func TrackedFiles() ([]string, error) {
+	gitLsFiles := exec.Command("git", "ls-files")
+
+	gitOut, err := gitLsFiles.Output()
+	if err != nil {
+		if ee, ok := err.(*exec.ExitError); ok {
+			return []string{}, fmt.Errorf("cannot list tracked files:\n%s", ee.Stderr)
+		} else {
+			return []string{}, fmt.Errorf("cannot list tracked files:\n%s", err)
+		}
+	}
+
+	if len(gitOut) == 0 {
+		return []string{}, nil
+	}
+
+	return strings.Split(strings.TrimRight(string(gitOut), "\n"), "\n"), nil
+}
+
+// TreeFiles uses git to list the files in a revision with their blob
+// object IDs
+func TreeFiles(revision string) (map[string]string, error) {
+	gitLsTree := exec.Command("git", "ls-tree", "-r", "-z", revision)
+
+	gitOut, err := gitLsTree.Output()
+	if err != nil {
+		if ee, ok := err.(*exec.ExitError); ok {
+			return nil, fmt.Errorf("cannot list files of '%s':\n%s", revision, ee.Stderr)
+		} else {
+			return nil, fmt.Errorf("cannot list files of '%s':\n%s", revision, err)
+		}
+	}
+
+	files := map[string]string{}
+	for _, entry := range strings.Split(string(gitOut), "\x00") {
+		// <mode> SP <type> SP <object> TAB <file>
+		tab := strings.Index(entry, "\t")
+		if tab < 0 {
+			continue
+		}
+
+		fields := strings.Fields(entry[:tab])
+		if len(fields) == 3 && fields[1] == "blob" {
+			files[entry[tab+1:]] = fields[2]
+		}
+	}
+
+	return files, nil
+}
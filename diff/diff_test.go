@@ -59,3 +59,30 @@ func Test_Impacted(t *testing.T) {
 		})
 	}
 }
+
+func Test_combine(t *testing.T) {
+	changes := [][]string{
+		{"app1/main.go", "libs/lib1/lib.go", "libs/lib2/lib.go"},
+		{"libs/lib1/lib.go", "app2/main.go", "libs/lib2/lib.go", ""},
+	}
+
+	tests := []struct {
+		name        string
+		changes     [][]string
+		combination Combination
+		want        []string
+	}{
+		{"combines no changes", [][]string{{""}}, Union, []string{}},
+		{"keeps changes against one base", changes[:1], Intersection, []string{"app1/main.go", "libs/lib1/lib.go", "libs/lib2/lib.go"}},
+		{"combines a union", changes, Union, []string{"app1/main.go", "app2/main.go", "libs/lib1/lib.go", "libs/lib2/lib.go"}},
+		{"combines an intersection", changes, Intersection, []string{"libs/lib1/lib.go", "libs/lib2/lib.go"}},
+		{"defaults to a union", changes, 0, []string{"app1/main.go", "app2/main.go", "libs/lib1/lib.go", "libs/lib2/lib.go"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := combine(tt.changes, tt.combination); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("combine() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package diff
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Markers store the last commit at which each component built successfully
+type Markers interface {
+	// Get returns the commit of the last successful build of a component, if
+	// there is one
+	Get(component string) (string, bool, error)
+	// Set records the commit of a successful build of a component
+	Set(component string, commit string) error
+}
+
+// OpenMarkers opens markers at a location, which is either a namespace of git
+// refs (starting with refs/) or the path of a state file
+func OpenMarkers(location string) Markers {
+	if strings.HasPrefix(location, "refs/") {
+		return RefMarkers{Namespace: strings.TrimRight(location, "/")}
+	}
+
+	return FileMarkers{Path: location}
+}
+
+// ResolveCommit finds the full ID of a commit
+func ResolveCommit(revision string) (string, error) {
+	gitRevParse := exec.Command("git", "rev-parse", "--verify", "--quiet", revision+"^{commit}")
+
+	out, err := gitRevParse.Output()
+	if err != nil {
+		return "", fmt.Errorf("cannot find commit '%s'", revision)
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
+
+// RefMarkers store markers as git refs in a namespace, e.g.
+// refs/monobuild/success/libs%2Flib1. The refs can be shared by pushing and
+// fetching them.
+type RefMarkers struct {
+	Namespace string
+}
+
+// refName encodes a component into a valid name of a ref, escaping
+// everything except letters, digits, '-' and '_'
+func (r RefMarkers) refName(component string) string {
+	var b strings.Builder
+
+	for _, c := range []byte(component) {
+		if ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') || c == '-' || c == '_' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+
+	return r.Namespace + "/" + b.String()
+}
+
+// Get returns the commit of the last successful build of a component, if
+// there is one
+func (r RefMarkers) Get(component string) (string, bool, error) {
+	ref := r.refName(component)
+	gitShowRef := exec.Command("git", "show-ref", "--verify", "--quiet", ref)
+
+	if _, err := gitShowRef.Output(); err != nil {
+		if ee, ok := err.(*exec.ExitError); ok {
+			if ee.ExitCode() == 1 {
+				return "", false, nil // the component never built successfully
+			}
+
+			return "", false, fmt.Errorf("cannot read successful build of '%s':\n%s", component, ee.Stderr)
+		} else {
+			return "", false, fmt.Errorf("cannot read successful build of '%s':\n%s", component, err)
+		}
+	}
+
+	commit, err := ResolveCommit(ref)
+	if err != nil {
+		return "", false, err
+	}
+
+	return commit, true, nil
+}
+
+// Set records the commit of a successful build of a component
+func (r RefMarkers) Set(component string, commit string) error {
+	gitUpdateRef := exec.Command("git", "update-ref", r.refName(component), commit)
+
+	if _, err := gitUpdateRef.Output(); err != nil {
+		if ee, ok := err.(*exec.ExitError); ok {
+			return fmt.Errorf("cannot record successful build of '%s':\n%s", component, ee.Stderr)
+		} else {
+			return fmt.Errorf("cannot record successful build of '%s':\n%s", component, err)
+		}
+	}
+
+	return nil
+}
+
+// FileMarkers store markers in a JSON state file, mapping components to
+// commits
+type FileMarkers struct {
+	Path string
+}
+
+func (f FileMarkers) read() (map[string]string, error) {
+	markers := map[string]string{}
+
+	content, err := os.ReadFile(f.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return markers, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot read markers %s: %s", f.Path, err)
+	}
+
+	if err := json.Unmarshal(content, &markers); err != nil {
+		return nil, fmt.Errorf("cannot read markers %s: %s", f.Path, err)
+	}
+
+	return markers, nil
+}
+
+// Get returns the commit of the last successful build of a component, if
+// there is one
+func (f FileMarkers) Get(component string) (string, bool, error) {
+	markers, err := f.read()
+	if err != nil {
+		return "", false, err
+	}
+
+	commit, ok := markers[component]
+	return commit, ok, nil
+}
+
+// Set records the commit of a successful build of a component
+func (f FileMarkers) Set(component string, commit string) error {
+	markers, err := f.read()
+	if err != nil {
+		return err
+	}
+
+	markers[component] = commit
+
+	content, err := json.MarshalIndent(markers, "", "  ")
+	if err != nil {
+		return fmt.Errorf("cannot write markers %s: %s", f.Path, err)
+	}
+
+	if err := os.WriteFile(f.Path, append(content, '\n'), 0644); err != nil {
+		return fmt.Errorf("cannot write markers %s: %s", f.Path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,67 @@
+package diff
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestOpenMarkers(t *testing.T) {
+	tests := []struct {
+		name     string
+		location string
+		want     Markers
+	}{
+		{"opens a ref namespace", "refs/monobuild/success/", RefMarkers{Namespace: "refs/monobuild/success"}},
+		{"opens a state file", ".monobuild/markers.json", FileMarkers{Path: ".monobuild/markers.json"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := OpenMarkers(tt.location); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("OpenMarkers() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRefMarkers_refName(t *testing.T) {
+	markers := RefMarkers{Namespace: "refs/monobuild/success"}
+
+	tests := []struct {
+		component string
+		want      string
+	}{
+		{"app1", "refs/monobuild/success/app1"},
+		{"libs/lib-1_a", "refs/monobuild/success/libs%2Flib-1_a"},
+		{".hidden/web.site", "refs/monobuild/success/%2Ehidden%2Fweb%2Esite"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.component, func(t *testing.T) {
+			if got := markers.refName(tt.component); got != tt.want {
+				t.Errorf("RefMarkers.refName() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileMarkers(t *testing.T) {
+	markers := FileMarkers{Path: filepath.Join(t.TempDir(), "markers.json")}
+
+	if _, ok, err := markers.Get("app1"); ok || err != nil {
+		t.Errorf("Get() of a missing marker = %v, %v, want false, nil", ok, err)
+	}
+
+	for component, commit := range map[string]string{"app1": "abc", "libs/lib1": "def"} {
+		if err := markers.Set(component, commit); err != nil {
+			t.Fatalf("Set() error = %v", err)
+		}
+	}
+
+	commit, ok, err := markers.Get("libs/lib1")
+	if !ok || err != nil {
+		t.Fatalf("Get() = %v, %v, want true, nil", ok, err)
+	}
+	if commit != "def" {
+		t.Errorf("Get() = %v, want def", commit)
+	}
+}
@@ -5,6 +5,7 @@ go 1.17
 require (
 	github.com/bmatcuk/doublestar v1.3.4
 	github.com/spf13/cobra v1.2.1
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -0,0 +1,33 @@
+package graph
+
+// bitset is a fixed size set of vertex IDs
+type bitset []uint64
+
+func newBitset(size int) bitset {
+	return make(bitset, (size+63)/64)
+}
+
+func (b bitset) set(i int) {
+	b[i/64] |= 1 << uint(i%64)
+}
+
+func (b bitset) has(i int) bool {
+	return b[i/64]&(1<<uint(i%64)) != 0
+}
+
+// members returns the IDs in the set in ascending order
+func (b bitset) members() []int {
+	result := make([]int, 0)
+
+	for w, word := range b {
+		for bit := 0; word != 0; bit++ {
+			if word&1 != 0 {
+				result = append(result, w*64+bit)
+			}
+
+			word >>= 1
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,160 @@
+package graph
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charypar/monobuild/set"
+)
+
+// diagramSyntax describes how a diagram language writes the parts of a graph.
+// Vertices are identified as n<index> as not all languages allow quoting.
+type diagramSyntax struct {
+	header       func(highlighted bool) string
+	vertex       func(id int, label string, state string) string
+	clusterStart func(id int, dir string) string
+	clusterEnd   string
+	edge         func(from int, to int, colour int) string
+	footer       string
+}
+
+// diagram renders the selected part of the graph in a diagram language. With
+// schedule set, edges are reversed to show the order of builds. Vertices are
+// coloured by their state when the highlight has any vertices.
+func (g Graph) diagram(selection []string, highlight Highlight, schedule bool, syntax diagramSyntax) string {
+	var result strings.Builder
+
+	highlighted := len(highlight.Changed) > 0 || len(highlight.Impacted) > 0
+	state := highlight.states()
+
+	filter := set.New(selection)
+	selected := make([]string, 0, len(selection))
+	for _, v := range g.vertices {
+		if filter.Has(v) {
+			selected = append(selected, v)
+		}
+	}
+
+	dirs, members := []string{""}, map[string][]string{"": selected}
+	if highlight.Clusters {
+		dirs, members = clusters(selected)
+	}
+
+	result.WriteString(syntax.header(highlighted))
+
+	for i, dir := range dirs {
+		indent := "  "
+		if dir != "" {
+			result.WriteString(syntax.clusterStart(i, dir))
+			indent = "    "
+		}
+
+		for _, v := range members[dir] {
+			s := ""
+			if highlighted {
+				s = state(v)
+			}
+
+			result.WriteString(indent + syntax.vertex(g.index[v], v, s))
+		}
+
+		if dir != "" {
+			result.WriteString(syntax.clusterEnd)
+		}
+	}
+
+	g.eachSelected(selected, func(c string, deps Edges) {
+		for _, d := range deps {
+			if schedule {
+				result.WriteString("  " + syntax.edge(g.index[d.Label], g.index[c], d.Colour))
+				continue
+			}
+
+			result.WriteString("  " + syntax.edge(g.index[c], g.index[d.Label], d.Colour))
+		}
+	})
+
+	return result.String() + syntax.footer
+}
+
+var mermaidSyntax = diagramSyntax{
+	header: func(highlighted bool) string {
+		if !highlighted {
+			return "graph LR\n"
+		}
+
+		return "graph LR\n" +
+			"  classDef changed fill:#f4a582\n" +
+			"  classDef impacted fill:#fddbc7\n" +
+			"  classDef untouched fill:#fff,stroke:#999,color:#999\n"
+	},
+	vertex: func(id int, label string, state string) string {
+		if state == "" {
+			return fmt.Sprintf("n%d[\"%s\"]\n", id, label)
+		}
+
+		return fmt.Sprintf("n%d[\"%s\"]:::%s\n", id, label, state)
+	},
+	clusterStart: func(id int, dir string) string {
+		return fmt.Sprintf("  subgraph c%d [\"%s/\"]\n", id, dir)
+	},
+	clusterEnd: "  end\n",
+	edge: func(from int, to int, colour int) string {
+		if colour == Weak {
+			return fmt.Sprintf("n%d -.-> n%d\n", from, to)
+		}
+
+		return fmt.Sprintf("n%d --> n%d\n", from, to)
+	},
+}
+
+// Mermaid returns a text representation of the graph as a Mermaid flowchart,
+// with weak dependencies shown as dotted lines
+func (g Graph) Mermaid(selection []string, highlight Highlight) string {
+	return g.diagram(selection, highlight, false, mermaidSyntax)
+}
+
+// MermaidSchedule returns a text representation of the graph as a Mermaid
+// flowchart formatted as a schedule
+func (g Graph) MermaidSchedule(selection []string, highlight Highlight) string {
+	return g.diagram(selection, highlight, true, mermaidSyntax)
+}
+
+var plantUMLColours = map[string]string{
+	"changed":   " #f4a582",
+	"impacted":  " #fddbc7",
+	"untouched": " #white;line:grey;text:grey",
+}
+
+var plantUMLSyntax = diagramSyntax{
+	header: func(highlighted bool) string {
+		return "@startuml\nleft to right direction\n"
+	},
+	vertex: func(id int, label string, state string) string {
+		return fmt.Sprintf("rectangle \"%s\" as n%d%s\n", label, id, plantUMLColours[state])
+	},
+	clusterStart: func(id int, dir string) string {
+		return fmt.Sprintf("  package \"%s/\" {\n", dir)
+	},
+	clusterEnd: "  }\n",
+	edge: func(from int, to int, colour int) string {
+		if colour == Weak {
+			return fmt.Sprintf("n%d ..> n%d\n", from, to)
+		}
+
+		return fmt.Sprintf("n%d --> n%d\n", from, to)
+	},
+	footer: "@enduml\n",
+}
+
+// PlantUML returns a text representation of the graph as a PlantUML diagram,
+// with weak dependencies shown as dotted lines
+func (g Graph) PlantUML(selection []string, highlight Highlight) string {
+	return g.diagram(selection, highlight, false, plantUMLSyntax)
+}
+
+// PlantUMLSchedule returns a text representation of the graph as a PlantUML
+// diagram formatted as a schedule
+func (g Graph) PlantUMLSchedule(selection []string, highlight Highlight) string {
+	return g.diagram(selection, highlight, true, plantUMLSyntax)
+}
@@ -0,0 +1,45 @@
+package graph
+
+import "testing"
+
+func TestMermaid(t *testing.T) {
+	schedule := exampleDependencies.FilterEdges([]int{Strong})
+	highlight := Highlight{Changed: []string{"a"}, Impacted: []string{"a", "d"}}
+
+	tests := []struct {
+		name string
+		got  string
+	}{
+		{"mermaid_empty", New(map[string][]Edge{}).Mermaid([]string{}, Highlight{})},
+		{"mermaid", exampleDependencies.Mermaid([]string{"a", "b", "c", "d"}, Highlight{})},
+		{"mermaid_schedule", schedule.MermaidSchedule([]string{"a", "b", "c", "d", "e"}, Highlight{})},
+		{"mermaid_highlight", exampleDependencies.Mermaid(exampleDependencies.Vertices(), highlight)},
+		{"mermaid_clusters", exampleRepository.Mermaid(exampleRepository.Vertices(), Highlight{[]string{"libs/lib1"}, nil, true})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			golden(t, tt.name, tt.got)
+		})
+	}
+}
+
+func TestPlantUML(t *testing.T) {
+	schedule := exampleDependencies.FilterEdges([]int{Strong})
+	highlight := Highlight{Changed: []string{"a"}, Impacted: []string{"a", "d"}}
+
+	tests := []struct {
+		name string
+		got  string
+	}{
+		{"plantuml_empty", New(map[string][]Edge{}).PlantUML([]string{}, Highlight{})},
+		{"plantuml", exampleDependencies.PlantUML([]string{"a", "b", "c", "d"}, Highlight{})},
+		{"plantuml_schedule", schedule.PlantUMLSchedule([]string{"a", "b", "c", "d", "e"}, Highlight{})},
+		{"plantuml_highlight", exampleDependencies.PlantUML(exampleDependencies.Vertices(), highlight)},
+		{"plantuml_clusters", exampleRepository.PlantUML(exampleRepository.Vertices(), Highlight{[]string{"libs/lib1"}, nil, true})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			golden(t, tt.name, tt.got)
+		})
+	}
+}
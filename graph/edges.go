@@ -10,19 +10,24 @@ type Edge struct {
 // the same operations as set.Set
 type Edges []Edge
 
+func (edges Edges) labels() map[string]bool {
+	labels := make(map[string]bool, len(edges))
+	for _, e := range edges {
+		labels[e.Label] = true
+	}
+
+	return labels
+}
+
 // Without removes all members of the other Edges from the Edges
 func (edges Edges) Without(other Edges) Edges {
 	result := make(Edges, 0, len(edges))
+	remove := other.labels()
 
-Outer:
 	for _, e := range edges {
-		for _, o := range other {
-			if e.Label == o.Label {
-				continue Outer // skip e
-			}
+		if !remove[e.Label] {
+			result = append(result, e)
 		}
-
-		result = append(result, e)
 	}
 
 	return result
@@ -31,16 +36,15 @@ Outer:
 // Union adds all the members in the other Edges to the Edges
 func (edges Edges) Union(other Edges) Edges {
 	result := edges
+	present := edges.labels()
 
-Outer:
 	for _, o := range other {
-		for _, e := range edges {
-			if o.Label == e.Label {
-				continue Outer
-			}
+		if present[o.Label] {
+			continue
 		}
 
 		result = append(result, o)
+		present[o.Label] = true
 	}
 
 	return result
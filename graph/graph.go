@@ -2,142 +2,271 @@ package graph
 
 import (
 	"sort"
-
-	"github.com/charypar/monobuild/set"
 )
 
-// Graph is a DAG with string labeled vertices and int colored edges
+// edge is an edge between vertices identified by their IDs
+type edge struct {
+	to     int
+	colour int
+}
+
+// Graph is a DAG with string labeled vertices and int colored edges.
+//
+// Internally, vertices are identified by integer IDs, which are indices
+// into a sorted list of labels, so iterating vertices by ID iterates them
+// in label order. Edges are kept as adjacency lists sorted by target, along
+// with the reversed adjacency lists.
 type Graph struct {
-	edges map[string]Edges
+	vertices []string
+	index    map[string]int
+	edges    [][]edge
+	reverse  [][]edge
+}
+
+// byTarget sorts edges by target and colour
+type byTarget []edge
+
+func (es byTarget) Len() int      { return len(es) }
+func (es byTarget) Swap(i, j int) { es[i], es[j] = es[j], es[i] }
+func (es byTarget) Less(i, j int) bool {
+	if es[i].to != es[j].to {
+		return es[i].to < es[j].to
+	}
+
+	return es[i].colour < es[j].colour
+}
+
+func (es byTarget) sorted() bool {
+	for i := 1; i < len(es); i++ {
+		if es.Less(i, i-1) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// indexOf maps sorted vertex labels to their IDs
+func indexOf(vertices []string) map[string]int {
+	index := make(map[string]int, len(vertices))
+	for id, v := range vertices {
+		index[v] = id
+	}
+
+	return index
+}
+
+// fromAdjacency builds a graph from sorted vertex labels, their index and
+// adjacency lists indexed by vertex ID
+func fromAdjacency(vertices []string, index map[string]int, edges [][]edge) Graph {
+	inDegree := make([]int, len(vertices))
+	for _, es := range edges {
+		if !byTarget(es).sorted() {
+			sort.Stable(byTarget(es))
+		}
+
+		for _, e := range es {
+			inDegree[e.to]++
+		}
+	}
+
+	reverse := make([][]edge, len(vertices))
+	for id := range vertices {
+		reverse[id] = make([]edge, 0, inDegree[id])
+	}
+
+	for from, es := range edges {
+		for _, e := range es {
+			reverse[e.to] = append(reverse[e.to], edge{from, e.colour})
+		}
+	}
+
+	return Graph{vertices, index, edges, reverse}
 }
 
 // New creates a new Graph from a map of the shape
 // string: Edge
 // where Edge is a struct with a Label and a Colour
 func New(graph map[string][]Edge) Graph {
-	g := make(map[string]Edges)
-
-	// Copy and normalise the graph - every node needs a key in the map
+	// Normalise the graph - every node needs a vertex
+	labels := make(map[string]bool, len(graph))
 	for v, es := range graph {
-		g[v] = make(Edges, 0, len(es))
+		labels[v] = true
 
 		for _, e := range es {
-			g[v] = append(g[v], e)
+			labels[e.Label] = true
+		}
+	}
 
-			_, ok := graph[e.Label]
-			if !ok {
-				g[e.Label] = Edges{}
-			}
+	vertices := make([]string, 0, len(labels))
+	for v := range labels {
+		vertices = append(vertices, v)
+	}
+	sort.Strings(vertices)
+	index := indexOf(vertices)
+
+	edges := make([][]edge, len(vertices))
+	for id, v := range vertices {
+		edges[id] = make([]edge, 0, len(graph[v]))
+
+		for _, e := range graph[v] {
+			edges[id] = append(edges[id], edge{index[e.Label], e.Colour})
 		}
 	}
 
-	return Graph{g}
+	return fromAdjacency(vertices, index, edges)
 }
 
-// Vertices returns a full list of vertices in the graph
-func (g Graph) Vertices() []string {
-	vs := make([]string, 0, len(g.edges))
+// edgesFrom returns the edges going from a vertex as Edges
+func (g Graph) edgesFrom(id int) Edges {
+	result := make(Edges, 0, len(g.edges[id]))
+
+	for _, e := range g.edges[id] {
+		result = append(result, Edge{g.vertices[e.to], e.colour})
+	}
+
+	return result
+}
+
+// ids looks up IDs of the vertices, skipping unknown ones
+func (g Graph) ids(vertices []string) []int {
+	result := make([]int, 0, len(vertices))
 
-	for v := range g.edges {
-		vs = append(vs, v)
+	for _, v := range vertices {
+		if id, found := g.index[v]; found {
+			result = append(result, id)
+		}
+	}
+
+	return result
+}
+
+// labels returns the labels of the vertices in a set
+func (g Graph) labels(ids bitset) []string {
+	members := ids.members()
+	result := make([]string, 0, len(members))
+
+	for _, id := range members {
+		result = append(result, g.vertices[id])
 	}
 
-	sort.Strings(vs)
+	return result
+}
+
+// Vertices returns a full list of vertices in the graph
+func (g Graph) Vertices() []string {
+	vs := make([]string, len(g.vertices))
+	copy(vs, g.vertices)
+
 	return vs
 }
 
 // Children returns the vertices that are connected to given vertices with an edge
 func (g Graph) Children(vertices []string) []string {
-	all := set.New([]string{})
+	children := newBitset(len(g.vertices))
 
-	for _, vertex := range vertices {
-		grandchildren, found := g.edges[vertex]
-		if !found {
-			continue
+	for _, id := range g.ids(vertices) {
+		for _, e := range g.edges[id] {
+			children.set(e.to)
 		}
+	}
+
+	return g.labels(children)
+}
+
+// walk visits the graph breadth first from the vertices given, up to depth
+// levels (or without a limit if depth is negative), calling visit with each
+// newly discovered vertex and its distance
+func (g Graph) walk(vertices []int, depth int, visit func(id int, distance int)) bitset {
+	discovered := newBitset(len(g.vertices))
+	frontier := vertices
+
+	for level := 1; (depth < 0 || level <= depth) && len(frontier) > 0; level++ {
+		next := make([]int, 0)
 
-		for _, gc := range grandchildren {
-			all.Add(gc.Label)
+		for _, id := range frontier {
+			for _, e := range g.edges[id] {
+				if discovered.has(e.to) {
+					continue
+				}
+
+				discovered.set(e.to)
+				next = append(next, e.to)
+
+				if visit != nil {
+					visit(e.to, level)
+				}
+			}
 		}
-	}
 
-	result := all.AsStrings()
-	sort.Strings(result)
+		frontier = next
+	}
 
-	return result
+	return discovered
 }
 
 // Descendants returns all the vertices x for which a path to x exists from any of
 // the vertices given
 func (g Graph) Descendants(vertices []string) []string {
-	descendants := set.New(g.Children(vertices))
-	discovered := descendants
-
-	for discovered.Size() > 0 {
-		grandchildren := set.New(g.Children(discovered.AsStrings()))
+	return g.labels(g.walk(g.ids(vertices), -1, nil))
+}
 
-		discovered = grandchildren.Without(descendants)
-		descendants = descendants.Union(discovered)
-	}
+// Distances returns the length of the shortest path from any of the vertices
+// given to each of their descendants
+func (g Graph) Distances(vertices []string) map[string]int {
+	result := make(map[string]int)
 
-	result := descendants.AsStrings()
-	sort.Strings(result)
+	g.walk(g.ids(vertices), -1, func(id int, distance int) {
+		result[g.vertices[id]] = distance
+	})
 
 	return result
 }
 
-// Reverse returns a new graph with edges reversed
-func (g Graph) Reverse() Graph {
-	edges := make(map[string]Edges)
-
-	// loop over the map keys deterministically
-	sorted := make([]string, 0, len(g.edges))
-	for v := range g.edges {
-		sorted = append(sorted, v)
+// DescendantsWithin returns all the vertices x for which a path of at most
+// depth edges to x exists from any of the vertices given
+func (g Graph) DescendantsWithin(vertices []string, depth int) []string {
+	if depth < 1 {
+		return []string{}
 	}
-	sort.Strings(sorted)
-
-	// here
-	for _, v := range sorted {
-		_, ok := edges[v]
-		if !ok {
-			edges[v] = Edges{}
-		}
 
-		for _, e := range g.edges[v] {
-			_, ok := edges[e.Label]
-			if !ok {
-				edges[e.Label] = Edges{}
-			}
-
-			edges[e.Label] = append(edges[e.Label], Edge{v, e.Colour})
-		}
-	}
+	return g.labels(g.walk(g.ids(vertices), depth, nil))
+}
 
-	return Graph{edges}
+// Reverse returns a new graph with edges reversed
+func (g Graph) Reverse() Graph {
+	return Graph{g.vertices, g.index, g.reverse, g.edges}
 }
 
 // Subgraph filters the graph to only the nodes listed
 func (g Graph) Subgraph(nodes []string) Graph {
-	filter := set.New(nodes)
-	filtered := make(map[string]Edges, len(g.edges))
+	selected := newBitset(len(g.vertices))
+	for _, id := range g.ids(nodes) {
+		selected.set(id)
+	}
 
-	for v, es := range g.edges {
-		if !filter.Has(v) {
-			continue
-		}
+	kept := selected.members()
+	renumbered := make([]int, len(g.vertices))
+	vertices := make([]string, 0, len(kept))
+	for _, id := range kept {
+		renumbered[id] = len(vertices)
+		vertices = append(vertices, g.vertices[id])
+	}
 
-		filtered[v] = make(Edges, 0, len(es))
-		for _, e := range es {
-			if !filter.Has(e.Label) {
+	edges := make([][]edge, len(kept))
+	for newID, id := range kept {
+		edges[newID] = make([]edge, 0, len(g.edges[id]))
+
+		for _, e := range g.edges[id] {
+			if !selected.has(e.to) {
 				continue
 			}
 
-			filtered[v] = append(filtered[v], e)
+			edges[newID] = append(edges[newID], edge{renumbered[e.to], e.colour})
 		}
 	}
 
-	return Graph{filtered}
+	return fromAdjacency(vertices, indexOf(vertices), edges)
 }
 
 // FilterEdges returns a new graph with edges with a colour not present in
@@ -148,21 +277,16 @@ func (g Graph) FilterEdges(colours []int) Graph {
 		filter[c] = true
 	}
 
-	filtered := make(map[string]Edges, len(g.edges))
-
-	for v, es := range g.edges {
-		_, ok := filtered[v]
-		if !ok {
-			filtered[v] = make([]Edge, 0, len(es))
-		}
+	edges := make([][]edge, len(g.vertices))
+	for id, es := range g.edges {
+		edges[id] = make([]edge, 0, len(es))
 
 		for _, e := range es {
-			_, matches := filter[e.Colour]
-			if matches {
-				filtered[v] = append(filtered[v], e)
+			if filter[e.colour] {
+				edges[id] = append(edges[id], e)
 			}
 		}
 	}
 
-	return Graph{filtered}
+	return fromAdjacency(g.vertices, g.index, edges)
 }
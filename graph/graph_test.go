@@ -1,6 +1,8 @@
 package graph
 
 import (
+	"fmt"
+	"math/rand"
 	"reflect"
 	"testing"
 )
@@ -127,6 +129,133 @@ func TestGraph_Descendants(t *testing.T) {
 	}
 }
 
+func TestGraph_DescendantsWithin(t *testing.T) {
+	chain := New(map[string][]Edge{
+		"a": []Edge{{"b", 0}, {"e", 0}},
+		"b": []Edge{{"c", 0}},
+		"c": []Edge{{"d", 0}},
+		"e": []Edge{{"c", 0}},
+	})
+
+	tests := []struct {
+		name     string
+		graph    Graph
+		vertices []string
+		depth    int
+		want     []string
+	}{
+		{
+			"returns empty set on an empty graph",
+			New(map[string][]Edge{}),
+			[]string{"foo"},
+			2,
+			[]string{},
+		},
+		{
+			"returns empty set for zero depth",
+			chain,
+			[]string{"a"},
+			0,
+			[]string{},
+		},
+		{
+			"finds children with depth of one",
+			chain,
+			[]string{"a"},
+			1,
+			[]string{"b", "e"},
+		},
+		{
+			"finds descendants within depth",
+			chain,
+			[]string{"a"},
+			2,
+			[]string{"b", "c", "e"},
+		},
+		{
+			"finds all descendants with a large depth",
+			chain,
+			[]string{"a"},
+			10,
+			[]string{"b", "c", "d", "e"},
+		},
+		{
+			"finds descendants of multiple vertices",
+			chain,
+			[]string{"b", "e"},
+			1,
+			[]string{"c"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.graph.DescendantsWithin(tt.vertices, tt.depth)
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Graph.DescendantsWithin() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGraph_Distances(t *testing.T) {
+	tests := []struct {
+		name     string
+		graph    Graph
+		vertices []string
+		want     map[string]int
+	}{
+		{
+			"returns nothing on an empty graph",
+			New(map[string][]Edge{}),
+			[]string{"foo"},
+			map[string]int{},
+		},
+		{
+			"finds distances of children",
+			New(map[string][]Edge{"a": []Edge{{"b", 0}, {"c", 0}}}),
+			[]string{"a"},
+			map[string]int{"b": 1, "c": 1},
+		},
+		{
+			"finds the shortest distances",
+			New(map[string][]Edge{
+				"a": []Edge{{"b", 0}, {"d", 0}},
+				"b": []Edge{{"c", 0}},
+				"c": []Edge{{"d", 0}, {"e", 0}},
+			}),
+			[]string{"a"},
+			map[string]int{"b": 1, "c": 2, "d": 1, "e": 3},
+		},
+		{
+			"finds distances from multiple vertices",
+			New(map[string][]Edge{
+				"a": []Edge{{"b", 0}},
+				"b": []Edge{{"c", 0}},
+				"x": []Edge{{"c", 0}},
+			}),
+			[]string{"a", "x"},
+			map[string]int{"b": 1, "c": 1},
+		},
+		{
+			"includes vertices reachable through a cycle",
+			New(map[string][]Edge{
+				"a": []Edge{{"b", 0}},
+				"b": []Edge{{"a", 0}},
+			}),
+			[]string{"a"},
+			map[string]int{"a": 2, "b": 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.graph.Distances(tt.vertices); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Graph.Distances() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestGraph_Reverse(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -235,17 +364,37 @@ func TestNew(t *testing.T) {
 		{
 			"creates an empty graph",
 			map[string][]Edge{},
-			Graph{edges: map[string]Edges{}},
+			Graph{
+				vertices: []string{},
+				index:    map[string]int{},
+				edges:    [][]edge{},
+				reverse:  [][]edge{},
+			},
 		},
 		{
 			"normalises the graph adding nodes that don't have dependencies",
 			map[string][]Edge{
 				"a": []Edge{{"b", 0}},
 			},
-			Graph{edges: map[string]Edges{
-				"a": []Edge{{"b", 0}},
-				"b": []Edge{},
-			}},
+			Graph{
+				vertices: []string{"a", "b"},
+				index:    map[string]int{"a": 0, "b": 1},
+				edges:    [][]edge{{{1, 0}}, {}},
+				reverse:  [][]edge{{}, {{0, 0}}},
+			},
+		},
+		{
+			"sorts vertices and edges",
+			map[string][]Edge{
+				"c": []Edge{{"b", 1}, {"a", 0}},
+				"a": []Edge{{"b", 2}},
+			},
+			Graph{
+				vertices: []string{"a", "b", "c"},
+				index:    map[string]int{"a": 0, "b": 1, "c": 2},
+				edges:    [][]edge{{{1, 2}}, {}, {{0, 0}, {1, 1}}},
+				reverse:  [][]edge{{{2, 0}}, {{0, 2}, {2, 1}}, {}},
+			},
 		},
 	}
 	for _, tt := range tests {
@@ -256,3 +405,96 @@ func TestNew(t *testing.T) {
 		})
 	}
 }
+
+// largeGraph generates a layered DAG similar to a big monorepo, with
+// components depending on a few components in the layers below
+func largeGraph(size int, degree int) Graph {
+	random := rand.New(rand.NewSource(42))
+	edges := make(map[string][]Edge, size)
+
+	for i := 0; i < size; i++ {
+		name := fmt.Sprintf("component-%04d", i)
+		edges[name] = make([]Edge, 0, degree)
+
+		for d := 0; d < degree && i > 0; d++ {
+			target := fmt.Sprintf("component-%04d", random.Intn(i))
+			colour := Weak
+			if random.Intn(10) == 0 {
+				colour = Strong
+			}
+
+			edges[name] = append(edges[name], Edge{target, colour})
+		}
+	}
+
+	return New(edges)
+}
+
+var benchmarkGraph = largeGraph(4000, 10)
+
+func BenchmarkNew(b *testing.B) {
+	edges := make(map[string][]Edge, 4000)
+	for _, v := range benchmarkGraph.Vertices() {
+		edges[v] = benchmarkGraph.edgesFrom(benchmarkGraph.index[v])
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		New(edges)
+	}
+}
+
+func BenchmarkGraph_Children(b *testing.B) {
+	vertices := benchmarkGraph.Vertices()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		benchmarkGraph.Children(vertices[i%len(vertices) : i%len(vertices)+1])
+	}
+}
+
+func BenchmarkGraph_Descendants(b *testing.B) {
+	vertices := benchmarkGraph.Vertices()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		benchmarkGraph.Descendants(vertices[len(vertices)-1-i%100:])
+	}
+}
+
+func BenchmarkGraph_ReverseDescendants(b *testing.B) {
+	vertices := benchmarkGraph.Vertices()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		benchmarkGraph.Reverse().Descendants(vertices[i%100 : i%100+1])
+	}
+}
+
+func BenchmarkGraph_Subgraph(b *testing.B) {
+	vertices := benchmarkGraph.Vertices()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		benchmarkGraph.Subgraph(vertices[:len(vertices)/2])
+	}
+}
+
+func BenchmarkGraph_FilterEdges(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		benchmarkGraph.FilterEdges([]int{Strong})
+	}
+}
+
+func BenchmarkEdges_Union(b *testing.B) {
+	edges := benchmarkGraph.edgesFrom(3999)
+	other := benchmarkGraph.edgesFrom(3998)
+	for i := 0; i < 50; i++ {
+		edges = append(edges, benchmarkGraph.edgesFrom(3900+i)...)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		append(Edges{}, edges...).Union(other)
+	}
+}
@@ -0,0 +1,89 @@
+package graph
+
+// Levels returns the level of each vertex in the graph, which is zero for
+// vertices without children and one more than the highest level of their
+// children otherwise. For a build schedule, it is the number of builds which
+// have to run one after another before the vertex can be built.
+func (g Graph) Levels() (map[string]int, error) {
+	order, err := g.buildOrder()
+	if err != nil {
+		return nil, err
+	}
+
+	levels := make([]int, len(g.vertices))
+	result := make(map[string]int, len(g.vertices))
+
+	for _, id := range order {
+		for _, e := range g.edges[id] {
+			if levels[e.to]+1 > levels[id] {
+				levels[id] = levels[e.to] + 1
+			}
+		}
+
+		result[g.vertices[id]] = levels[id]
+	}
+
+	return result, nil
+}
+
+// Betweenness returns the betweenness centrality of each vertex, which is
+// the number of shortest paths between other vertices passing through it
+// (where several shortest paths exist between a pair of vertices, each counts
+// by its share). It uses Brandes' algorithm.
+func (g Graph) Betweenness() map[string]float64 {
+	size := len(g.vertices)
+	centrality := make([]float64, size)
+
+	distance := make([]int, size)
+	paths := make([]float64, size)
+	dependency := make([]float64, size)
+	predecessors := make([][]int, size)
+
+	for source := 0; source < size; source++ {
+		for v := 0; v < size; v++ {
+			distance[v] = -1
+			paths[v] = 0
+			dependency[v] = 0
+			predecessors[v] = predecessors[v][:0]
+		}
+
+		distance[source] = 0
+		paths[source] = 1
+
+		// breadth first search counting shortest paths
+		visited := []int{source}
+		for i := 0; i < len(visited); i++ {
+			v := visited[i]
+
+			for _, e := range g.edges[v] {
+				if distance[e.to] < 0 {
+					distance[e.to] = distance[v] + 1
+					visited = append(visited, e.to)
+				}
+
+				if distance[e.to] == distance[v]+1 {
+					paths[e.to] += paths[v]
+					predecessors[e.to] = append(predecessors[e.to], v)
+				}
+			}
+		}
+
+		// accumulate dependencies from the furthest vertices back
+		for i := len(visited) - 1; i > 0; i-- {
+			w := visited[i]
+
+			for _, v := range predecessors[w] {
+				dependency[v] += paths[v] / paths[w] * (1 + dependency[w])
+			}
+
+			centrality[w] += dependency[w]
+		}
+	}
+
+	result := make(map[string]float64, size)
+	for id, v := range g.vertices {
+		result[v] = centrality[id]
+	}
+
+	return result
+}
@@ -0,0 +1,78 @@
+package graph
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGraph_Levels(t *testing.T) {
+	want := map[string]int{
+		"app1":   1,
+		"app2":   1,
+		"lib1":   0,
+		"lib2":   0,
+		"stack1": 2,
+		"tool":   0,
+	}
+
+	got, err := exampleSchedule.Levels()
+	if err != nil {
+		t.Fatalf("Graph.Levels() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Graph.Levels() = %v, want %v", got, want)
+	}
+
+	_, err = New(map[string][]Edge{"a": []Edge{{"b", Strong}}, "b": []Edge{{"a", Strong}}}).Levels()
+	if err == nil {
+		t.Errorf("Graph.Levels() expected an error for a cycle")
+	}
+}
+
+func TestGraph_Betweenness(t *testing.T) {
+	tests := []struct {
+		name  string
+		graph Graph
+		want  map[string]float64
+	}{
+		{
+			"returns nothing for an empty graph",
+			New(map[string][]Edge{}),
+			map[string]float64{},
+		},
+		{
+			"counts paths through the middle of a chain",
+			New(map[string][]Edge{
+				"a": []Edge{{"b", Weak}},
+				"b": []Edge{{"c", Weak}},
+				"c": []Edge{{"d", Weak}},
+			}),
+			map[string]float64{"a": 0, "b": 2, "c": 2, "d": 0},
+		},
+		{
+			"splits paths between shortest alternatives",
+			New(map[string][]Edge{
+				"a": []Edge{{"b", Weak}, {"c", Weak}},
+				"b": []Edge{{"d", Weak}},
+				"c": []Edge{{"d", Weak}},
+			}),
+			map[string]float64{"a": 0, "b": 0.5, "c": 0.5, "d": 0},
+		},
+		{
+			"ignores longer paths",
+			New(map[string][]Edge{
+				"a": []Edge{{"b", Weak}, {"c", Weak}},
+				"b": []Edge{{"c", Weak}},
+			}),
+			map[string]float64{"a": 0, "b": 0, "c": 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.graph.Betweenness(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Graph.Betweenness() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package graph
 
 import (
 	"fmt"
+	"path"
 	"sort"
 	"strings"
 
@@ -14,118 +15,196 @@ var Weak = 1
 // Strong shows as solid line
 var Strong = 2
 
-// Text returns graph as text suitable for output
-func (g Graph) Text(selection []string, showType bool) string {
-	var result string
+// eachSelected calls visit with every selected vertex in sorted order and its
+// edges to other selected vertices, sorted by their targets. All renderers use
+// it, so that their output is deterministic.
+func (g Graph) eachSelected(selection []string, visit func(vertex string, edges Edges)) {
 	filter := set.New(selection)
 
-	cs := make([]string, len(g.edges))
-	for c := range g.edges {
-		cs = append(cs, c)
-	}
-	sort.Strings(cs)
-
-	for _, c := range cs {
-		if !filter.Has(c) {
+	for id, v := range g.vertices {
+		if !filter.Has(v) {
 			continue
 		}
 
-		d := g.edges[c]
-
-		names := make([]string, 0, len(d))
-		for _, v := range d {
-			if !filter.Has(v.Label) {
-				continue
+		edges := make(Edges, 0, len(g.edges[id]))
+		for _, e := range g.edgesFrom(id) {
+			if filter.Has(e.Label) {
+				edges = append(edges, e)
 			}
+		}
+		sort.Sort(edges)
+
+		visit(v, edges)
+	}
+}
 
-			label := v.Label
-			if showType && v.Colour == Strong {
-				label = "!" + v.Label
+// Text returns graph as text suitable for output
+func (g Graph) Text(selection []string, showType bool) string {
+	var result strings.Builder
+
+	g.eachSelected(selection, func(c string, deps Edges) {
+		names := make([]string, 0, len(deps))
+		for _, d := range deps {
+			label := d.Label
+			if showType && d.Colour == Strong {
+				label = "!" + d.Label
 			}
 
 			names = append(names, label)
 		}
 		sort.Strings(names)
 
-		result += fmt.Sprintf("%s: %s\n", c, strings.Join(names, ", "))
-	}
+		fmt.Fprintf(&result, "%s: %s\n", c, strings.Join(names, ", "))
+	})
 
-	return result
+	return result.String()
 }
 
 // Dot returns a simple text representation of the graph in the DOT language
 func (g Graph) Dot(selection []string) string {
-	result := fmt.Sprintln("digraph dependencies {")
-	filter := set.New(selection)
+	var result strings.Builder
+	fmt.Fprintln(&result, "digraph dependencies {")
 
-	cs := make([]string, len(g.edges))
-	for c := range g.edges {
-		cs = append(cs, c)
-	}
-	sort.Strings(cs)
+	g.eachSelected(selection, func(c string, deps Edges) {
+		if len(deps) < 1 {
+			fmt.Fprintf(&result, "  \"%s\"\n", c)
+		}
 
-	for _, c := range cs {
-		if !filter.Has(c) {
-			continue
+		for _, d := range deps {
+			var format string
+			if d.Colour == Weak { // FIXME clean up when --full is supported? Breaking change...
+				format = " [style=dashed]"
+			}
+
+			fmt.Fprintf(&result, "  \"%s\" -> \"%s\"%s\n", c, d.Label, format)
 		}
+	})
+
+	return result.String() + "}\n"
+}
 
-		deps := g.edges[c]
+// DotSchedule returns a text representation of the graph in the DOT language
+// formatted as a schedule
+func (g Graph) DotSchedule(selection []string) string {
+	var result strings.Builder
+	fmt.Fprintln(&result, "digraph schedule {\n  rankdir=\"LR\"\n  node [shape=box]")
+
+	g.eachSelected(selection, func(c string, deps Edges) {
+		if len(deps) < 1 {
+			fmt.Fprintf(&result, "  \"%s\"\n", c)
+		}
 
-		noDeps := true
 		for _, d := range deps {
-			if filter.Has(d.Label) {
-				noDeps = false
-				break
-			}
+			// reverse the graph during print
+			fmt.Fprintf(&result, "  \"%s\" -> \"%s\"\n", d.Label, c)
 		}
+	})
 
-		if noDeps {
-			result += fmt.Sprintf("  \"%s\"\n", c)
+	return result.String() + "}\n"
+}
+
+// Highlight holds the options of a highlighted rendering of the graph
+type Highlight struct {
+	Changed  []string // Vertices changed directly
+	Impacted []string // Vertices impacted by the changes
+	Clusters bool     // Group vertices into clusters by their parent directory
+}
+
+// state of a vertex in a highlighted rendering
+func (h Highlight) states() func(vertex string) string {
+	changed := set.New(h.Changed)
+	impacted := set.New(h.Impacted)
+
+	return func(vertex string) string {
+		if changed.Has(vertex) {
+			return "changed"
 		}
 
-		sort.Sort(deps)
+		if impacted.Has(vertex) {
+			return "impacted"
+		}
 
-		for _, d := range deps {
-			if !filter.Has(d.Label) {
-				continue
-			}
+		return "untouched"
+	}
+}
 
-			var format string
-			if d.Colour == Weak { // FIXME clean up when --full is supported? Breaking change...
-				format = " [style=dashed]"
-			}
+// clusters groups vertices by their parent directory, vertices at the top
+// level are grouped under ""
+func clusters(vertices []string) ([]string, map[string][]string) {
+	dirs := []string{}
+	members := make(map[string][]string)
+
+	for _, v := range vertices {
+		dir := path.Dir(v)
+		if dir == "." {
+			dir = ""
+		}
 
-			result += fmt.Sprintf("  \"%s\" -> \"%s\"%s\n", c, d.Label, format)
+		if _, ok := members[dir]; !ok {
+			dirs = append(dirs, dir)
 		}
+		members[dir] = append(members[dir], v)
 	}
+	sort.Strings(dirs)
 
-	return result + "}\n"
+	return dirs, members
 }
 
-// DotSchedule returns a text representation of the graph in the DOT language
-// formatted as a schedule
-func (g Graph) DotSchedule(selection []string) string {
-	result := fmt.Sprintln("digraph schedule {\n  rankdir=\"LR\"\n  node [shape=box]")
-	filter := set.New(selection)
+var dotHighlightStyles = map[string]string{
+	"changed":   `fillcolor="#f4a582"`,
+	"impacted":  `fillcolor="#fddbc7"`,
+	"untouched": `fillcolor="white", color="grey", fontcolor="grey"`,
+}
 
-	for c, deps := range g.edges {
-		if !filter.Has(c) {
-			continue
+// DotHighlight returns the whole graph in the DOT language with changed,
+// impacted and untouched vertices coloured differently and edges labeled
+// with their strength. With schedule set, the graph is formatted as a schedule
+// and edges are reversed to show the order of builds.
+func (g Graph) DotHighlight(highlight Highlight, schedule bool) string {
+	var result strings.Builder
+	if schedule {
+		fmt.Fprintln(&result, "digraph schedule {\n  rankdir=\"LR\"\n  node [shape=box, style=filled]")
+	} else {
+		fmt.Fprintln(&result, "digraph dependencies {\n  node [style=filled]")
+	}
+
+	state := highlight.states()
+	dirs, members := clusters(g.vertices)
+	if !highlight.Clusters {
+		dirs, members = []string{""}, map[string][]string{"": g.vertices}
+	}
+
+	for _, dir := range dirs {
+		indent := "  "
+		if dir != "" {
+			fmt.Fprintf(&result, "  subgraph \"cluster_%s\" {\n    label=\"%s/\"\n", dir, dir)
+			indent = "    "
 		}
 
-		if len(deps) < 1 {
-			result += fmt.Sprintf("  \"%s\"\n", c)
+		for _, v := range members[dir] {
+			fmt.Fprintf(&result, "%s\"%s\" [%s]\n", indent, v, dotHighlightStyles[state(v)])
+		}
+
+		if dir != "" {
+			fmt.Fprintln(&result, "  }")
 		}
+	}
 
+	g.eachSelected(g.vertices, func(c string, deps Edges) {
 		for _, d := range deps {
-			if !filter.Has(d.Label) {
+			format := `label="strong"`
+			if d.Colour == Weak {
+				format = `style=dashed, label="weak"`
+			}
+
+			if schedule {
+				fmt.Fprintf(&result, "  \"%s\" -> \"%s\" [%s]\n", d.Label, c, format)
 				continue
 			}
 
-			// reverse the graph during print
-			result += fmt.Sprintf("  \"%s\" -> \"%s\"\n", d.Label, c)
+			fmt.Fprintf(&result, "  \"%s\" -> \"%s\" [%s]\n", c, d.Label, format)
 		}
-	}
+	})
 
-	return result + "}\n"
+	return result.String() + "}\n"
 }
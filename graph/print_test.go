@@ -1,9 +1,36 @@
 package graph
 
 import (
+	"flag"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
+var update = flag.Bool("update", false, "update golden files in testdata")
+
+// golden compares output with the golden file testdata/<name>.golden, or
+// overwrites the file with the output when run with -update
+func golden(t *testing.T, name string, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden")
+
+	if *update {
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatalf("cannot update golden file: %s", err)
+		}
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("cannot read golden file: %s", err)
+	}
+
+	if got != string(want) {
+		t.Errorf("output does not match %s\ngot:\n%s\nwant:\n%s", path, got, want)
+	}
+}
+
 var exampleDependencies = New(map[string][]Edge{
 	"a": []Edge{{Label: "c", Colour: Weak}, {Label: "b", Colour: Weak}},
 	"b": []Edge{{Label: "c", Colour: Weak}},
@@ -140,19 +167,64 @@ func TestDot(t *testing.T) {
 }
 
 func TestDotSchedule(t *testing.T) {
+	schedule := exampleDependencies.FilterEdges([]int{Strong})
+
 	tests := []struct {
 		name      string
 		graph     Graph
 		selection []string
-		want      string
 	}{
-		// TODO: Add test cases.
+		{"dot_schedule_empty", New(map[string][]Edge{}), []string{}},
+		{"dot_schedule_full", schedule, []string{"a", "b", "c", "d", "e"}},
+		{"dot_schedule_selection", schedule, []string{"a", "c", "e"}},
+		{"dot_schedule_all_edges", exampleDependencies, []string{"a", "b", "c", "d", "e"}},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := tt.graph.DotSchedule(tt.selection); got != tt.want {
-				t.Errorf("DotSchedule() = %v, want %v", got, tt.want)
+			got := tt.graph.DotSchedule(tt.selection)
+			golden(t, tt.name, got)
+
+			// output doesn't depend on the order of the selection
+			reversed := make([]string, 0, len(tt.selection))
+			for i := len(tt.selection) - 1; i >= 0; i-- {
+				reversed = append(reversed, tt.selection[i])
 			}
+
+			if again := tt.graph.DotSchedule(reversed); again != got {
+				t.Errorf("DotSchedule() is not deterministic, got %v, then %v", got, again)
+			}
+		})
+	}
+}
+
+var exampleRepository = New(map[string][]Edge{
+	"apps/app1": []Edge{{Label: "libs/lib1", Colour: Weak}},
+	"apps/app2": []Edge{{Label: "libs/lib2", Colour: Weak}},
+	"libs/lib1": []Edge{{Label: "libs/lib2", Colour: Weak}},
+	"libs/lib2": []Edge{},
+	"stack1":    []Edge{{Label: "apps/app1", Colour: Strong}, {Label: "apps/app2", Colour: Strong}},
+})
+
+func TestDotHighlight(t *testing.T) {
+	highlight := Highlight{
+		Changed:  []string{"libs/lib1"},
+		Impacted: []string{"apps/app1", "libs/lib1", "stack1"},
+	}
+
+	tests := []struct {
+		name      string
+		graph     Graph
+		highlight Highlight
+		schedule  bool
+	}{
+		{"dot_highlight_empty", New(map[string][]Edge{}), Highlight{}, false},
+		{"dot_highlight", exampleRepository, highlight, false},
+		{"dot_highlight_clusters", exampleRepository, Highlight{highlight.Changed, highlight.Impacted, true}, false},
+		{"dot_highlight_schedule", exampleRepository, highlight, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			golden(t, tt.name, tt.graph.DotHighlight(tt.highlight, tt.schedule))
 		})
 	}
 }
@@ -0,0 +1,72 @@
+package graph
+
+// redundant finds edges of each vertex which are implied by a longer path.
+// A strong edge is only implied by a path of strong edges, so that reducing
+// the graph never changes the build schedule.
+func (g Graph) redundant() []bitset {
+	strong := g.FilterEdges([]int{Strong})
+	result := make([]bitset, len(g.vertices))
+
+	for id, es := range g.edges {
+		children := make([]int, 0, len(es))
+		for _, e := range es {
+			children = append(children, e.to)
+		}
+
+		strongChildren := make([]int, 0, len(strong.edges[id]))
+		for _, e := range strong.edges[id] {
+			strongChildren = append(strongChildren, e.to)
+		}
+
+		// vertices reachable through a path of at least two edges
+		reachable := g.walk(children, -1, nil)
+		strongReachable := strong.walk(strongChildren, -1, nil)
+
+		result[id] = newBitset(len(es))
+		for i, e := range es {
+			if (e.colour == Strong && strongReachable.has(e.to)) || (e.colour != Strong && reachable.has(e.to)) {
+				result[id].set(i)
+			}
+		}
+	}
+
+	return result
+}
+
+// TransitiveReduction returns a new graph without the edges implied by other
+// paths in the graph, e.g. if a depends on b and c, and b depends on c,
+// the edge from a to c is removed. Strong edges are only removed if they are
+// implied by a path of strong edges.
+func (g Graph) TransitiveReduction() Graph {
+	redundant := g.redundant()
+
+	edges := make([][]edge, len(g.vertices))
+	for id, es := range g.edges {
+		edges[id] = make([]edge, 0, len(es))
+
+		for i, e := range es {
+			if !redundant[id].has(i) {
+				edges[id] = append(edges[id], e)
+			}
+		}
+	}
+
+	return fromAdjacency(g.vertices, g.index, edges)
+}
+
+// RedundantEdges returns the edges of each vertex which are removed by
+// TransitiveReduction. Vertices without redundant edges are left out.
+func (g Graph) RedundantEdges() map[string]Edges {
+	redundant := g.redundant()
+	result := make(map[string]Edges)
+
+	for id, es := range g.edges {
+		for i, e := range es {
+			if redundant[id].has(i) {
+				result[g.vertices[id]] = append(result[g.vertices[id]], Edge{g.vertices[e.to], e.colour})
+			}
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,101 @@
+package graph
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGraph_TransitiveReduction(t *testing.T) {
+	tests := []struct {
+		name  string
+		graph Graph
+		want  Graph
+	}{
+		{
+			"reduces an empty graph",
+			New(map[string][]Edge{}),
+			New(map[string][]Edge{}),
+		},
+		{
+			"keeps a graph without redundant edges",
+			New(map[string][]Edge{
+				"a": []Edge{{"b", Weak}, {"c", Weak}},
+				"b": []Edge{{"d", Weak}},
+			}),
+			New(map[string][]Edge{
+				"a": []Edge{{"b", Weak}, {"c", Weak}},
+				"b": []Edge{{"d", Weak}},
+			}),
+		},
+		{
+			"removes an edge implied by a path",
+			New(map[string][]Edge{
+				"a": []Edge{{"b", Weak}, {"c", Weak}},
+				"b": []Edge{{"c", Weak}},
+			}),
+			New(map[string][]Edge{
+				"a": []Edge{{"b", Weak}},
+				"b": []Edge{{"c", Weak}},
+			}),
+		},
+		{
+			"removes an edge implied by a long path",
+			New(map[string][]Edge{
+				"a": []Edge{{"b", Weak}, {"d", Weak}},
+				"b": []Edge{{"c", Strong}},
+				"c": []Edge{{"d", Weak}},
+			}),
+			New(map[string][]Edge{
+				"a": []Edge{{"b", Weak}},
+				"b": []Edge{{"c", Strong}},
+				"c": []Edge{{"d", Weak}},
+			}),
+		},
+		{
+			"removes a strong edge implied by a strong path",
+			New(map[string][]Edge{
+				"a": []Edge{{"b", Strong}, {"c", Strong}},
+				"b": []Edge{{"c", Strong}},
+			}),
+			New(map[string][]Edge{
+				"a": []Edge{{"b", Strong}},
+				"b": []Edge{{"c", Strong}},
+			}),
+		},
+		{
+			"keeps a strong edge implied by a weak path",
+			New(map[string][]Edge{
+				"a": []Edge{{"b", Strong}, {"c", Strong}},
+				"b": []Edge{{"c", Weak}},
+			}),
+			New(map[string][]Edge{
+				"a": []Edge{{"b", Strong}, {"c", Strong}},
+				"b": []Edge{{"c", Weak}},
+			}),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.graph.TransitiveReduction(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Graph.TransitiveReduction() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGraph_RedundantEdges(t *testing.T) {
+	graph := New(map[string][]Edge{
+		"a": []Edge{{"b", Weak}, {"c", Strong}, {"d", Weak}},
+		"b": []Edge{{"c", Weak}, {"d", Weak}},
+		"c": []Edge{{"d", Strong}},
+	})
+
+	want := map[string]Edges{
+		"a": Edges{{"d", Weak}},
+		"b": Edges{{"d", Weak}},
+	}
+
+	if got := graph.RedundantEdges(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Graph.RedundantEdges() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,176 @@
+package graph
+
+import (
+	"fmt"
+	"sort"
+)
+
+// buildOrder returns vertex IDs in an order where every vertex comes after
+// all of its children, or an error if the graph has a cycle
+func (g Graph) buildOrder() ([]int, error) {
+	pending := make([]int, len(g.vertices))
+	ready := make([]int, 0)
+
+	for id, es := range g.edges {
+		pending[id] = len(es)
+		if len(es) == 0 {
+			ready = append(ready, id)
+		}
+	}
+
+	order := make([]int, 0, len(g.vertices))
+	for len(ready) > 0 {
+		id := ready[0]
+		ready = ready[1:]
+		order = append(order, id)
+
+		for _, e := range g.reverse[id] {
+			pending[e.to]--
+			if pending[e.to] == 0 {
+				ready = append(ready, e.to)
+			}
+		}
+	}
+
+	if len(order) < len(g.vertices) {
+		cyclic := []string{}
+		for id, p := range pending {
+			if p > 0 {
+				cyclic = append(cyclic, g.vertices[id])
+			}
+		}
+
+		return nil, fmt.Errorf("dependency cycle between %v", cyclic)
+	}
+
+	return order, nil
+}
+
+// CriticalPath returns the chain of vertices with the highest total cost,
+// in build order (each vertex depends on the one before it), and its cost.
+// Vertices missing from costs cost nothing.
+func (g Graph) CriticalPath(costs map[string]float64) ([]string, float64, error) {
+	order, err := g.buildOrder()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// finish is the cost of the most expensive chain ending with the vertex
+	finish := make([]float64, len(g.vertices))
+	previous := make([]int, len(g.vertices))
+	last := -1
+
+	for _, id := range order {
+		previous[id] = -1
+
+		for _, e := range g.edges[id] {
+			if previous[id] < 0 || finish[e.to] > finish[previous[id]] {
+				previous[id] = e.to
+			}
+		}
+
+		finish[id] = costs[g.vertices[id]]
+		if previous[id] >= 0 {
+			finish[id] += finish[previous[id]]
+		}
+
+		if last < 0 || finish[id] >= finish[last] {
+			last = id
+		}
+	}
+
+	if last < 0 {
+		return []string{}, 0, nil
+	}
+
+	path := []string{}
+	for id := last; id >= 0; id = previous[id] {
+		path = append([]string{g.vertices[id]}, path...)
+	}
+
+	return path, finish[last], nil
+}
+
+// Makespan estimates the total time it takes to process all the vertices
+// with a number of parallel workers (unlimited if less than one), where
+// each vertex can only start once all of its children are finished.
+// Finding the optimal schedule is NP-hard, the estimate is the length of
+// a schedule always starting the vertex with the most expensive chain of
+// dependents first, which is close to optimal in practice.
+func (g Graph) Makespan(costs map[string]float64, workers int) (float64, error) {
+	order, err := g.buildOrder()
+	if err != nil {
+		return 0, err
+	}
+
+	if workers < 1 {
+		workers = len(g.vertices)
+	}
+
+	// priority is the cost of the most expensive chain starting with the vertex
+	priority := make([]float64, len(g.vertices))
+	for i := len(order) - 1; i >= 0; i-- {
+		id := order[i]
+
+		for _, e := range g.reverse[id] {
+			if priority[e.to] > priority[id] {
+				priority[id] = priority[e.to]
+			}
+		}
+		priority[id] += costs[g.vertices[id]]
+	}
+
+	type task struct {
+		id     int
+		finish float64
+	}
+
+	pending := make([]int, len(g.vertices))
+	ready := make([]int, 0)
+	for id, es := range g.edges {
+		pending[id] = len(es)
+		if len(es) == 0 {
+			ready = append(ready, id)
+		}
+	}
+
+	running := make([]task, 0, workers)
+	now := 0.0
+
+	for len(ready) > 0 || len(running) > 0 {
+		// start the most important ready vertices on free workers
+		sort.SliceStable(ready, func(i, j int) bool {
+			if priority[ready[i]] != priority[ready[j]] {
+				return priority[ready[i]] > priority[ready[j]]
+			}
+
+			return ready[i] < ready[j]
+		})
+
+		for len(running) < workers && len(ready) > 0 {
+			running = append(running, task{ready[0], now + costs[g.vertices[ready[0]]]})
+			ready = ready[1:]
+		}
+
+		// wait for the first running vertex to finish
+		first := 0
+		for i, t := range running {
+			if t.finish < running[first].finish {
+				first = i
+			}
+		}
+
+		done := running[first]
+		running = append(running[:first], running[first+1:]...)
+		now = done.finish
+
+		for _, e := range g.reverse[done.id] {
+			pending[e.to]--
+			if pending[e.to] == 0 {
+				ready = append(ready, e.to)
+			}
+		}
+	}
+
+	return now, nil
+}
@@ -0,0 +1,107 @@
+package graph
+
+import (
+	"reflect"
+	"testing"
+)
+
+var exampleSchedule = New(map[string][]Edge{
+	"app1":   []Edge{{"lib1", Strong}, {"lib2", Strong}},
+	"app2":   []Edge{{"lib2", Strong}},
+	"lib1":   []Edge{},
+	"lib2":   []Edge{},
+	"stack1": []Edge{{"app1", Strong}, {"app2", Strong}},
+	"tool":   []Edge{},
+})
+
+var exampleCosts = map[string]float64{
+	"app1":   2,
+	"app2":   5,
+	"lib1":   4,
+	"lib2":   1,
+	"stack1": 1,
+	"tool":   3,
+}
+
+func TestGraph_CriticalPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		graph    Graph
+		costs    map[string]float64
+		want     []string
+		wantCost float64
+		wantErr  bool
+	}{
+		{
+			"returns an empty path for an empty graph",
+			New(map[string][]Edge{}),
+			map[string]float64{},
+			[]string{},
+			0,
+			false,
+		},
+		{
+			"finds the most expensive chain",
+			exampleSchedule,
+			exampleCosts,
+			[]string{"lib1", "app1", "stack1"},
+			7,
+			false,
+		},
+		{
+			"treats missing costs as zero",
+			exampleSchedule,
+			map[string]float64{"app2": 10},
+			[]string{"lib2", "app2", "stack1"},
+			10,
+			false,
+		},
+		{
+			"fails on a cycle",
+			New(map[string][]Edge{"a": []Edge{{"b", Strong}}, "b": []Edge{{"a", Strong}}}),
+			map[string]float64{},
+			nil,
+			0,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, cost, err := tt.graph.CriticalPath(tt.costs)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Graph.CriticalPath() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Graph.CriticalPath() got = %v, want %v", got, tt.want)
+			}
+			if cost != tt.wantCost {
+				t.Errorf("Graph.CriticalPath() cost = %v, want %v", cost, tt.wantCost)
+			}
+		})
+	}
+}
+
+func TestGraph_Makespan(t *testing.T) {
+	tests := []struct {
+		name    string
+		workers int
+		want    float64
+	}{
+		{"takes the sum of costs with a single worker", 1, 16},
+		{"is limited by the critical path with enough workers", 0, 7},
+		{"schedules expensive chains first", 2, 9},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := exampleSchedule.Makespan(exampleCosts, tt.workers)
+			if err != nil {
+				t.Errorf("Graph.Makespan() error = %v", err)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Graph.Makespan() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
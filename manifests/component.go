@@ -0,0 +1,144 @@
+package manifests
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Commands holds the commands used to build, test and deploy a component
+type Commands struct {
+	Build  string `yaml:"build"`
+	Test   string `yaml:"test"`
+	Deploy string `yaml:"deploy"`
+}
+
+// Component holds a component with its dependencies and metadata.
+// Components declared with a plain Dependencies manifest only have a name and
+// dependencies, structured component manifests can declare the rest.
+type Component struct {
+	Name         string
+	Dependencies []Dependency
+	Owners       []string
+	Tags         []string
+	Commands     Commands
+	Inputs       []string // glob patterns of input files, relative to the component
+	Timeout      time.Duration
+}
+
+// componentDependency is a dependency declared in a component manifest, either
+// as a string (using the same syntax as the Dependencies manifest) or as
+// a mapping with a name and a kind
+type componentDependency struct {
+	Dependency
+	line int
+}
+
+func (d *componentDependency) UnmarshalYAML(value *yaml.Node) error {
+	d.line = value.Line
+
+	if value.Kind == yaml.ScalarNode {
+		dep, err := readDependency(value.Value)
+		if err != nil {
+			return err
+		}
+
+		d.Dependency = dep
+		return nil
+	}
+
+	var declared struct {
+		Name string `yaml:"name"`
+		Kind string `yaml:"kind"`
+	}
+	if err := value.Decode(&declared); err != nil {
+		return err
+	}
+
+	d.Name = strings.TrimRight(strings.TrimSpace(declared.Name), "/")
+
+	switch declared.Kind {
+	case "", "weak":
+		d.Kind = Weak
+	case "strong":
+		d.Kind = Strong
+	default:
+		return fmt.Errorf("line %d: unknown dependency kind '%s', expected 'weak' or 'strong'", value.Line, declared.Kind)
+	}
+
+	return nil
+}
+
+// componentManifest is the structure of a component manifest file
+type componentManifest struct {
+	Dependencies []componentDependency `yaml:"dependencies"`
+	Owners       []string              `yaml:"owners"`
+	Tags         []string              `yaml:"tags"`
+	Commands     Commands              `yaml:"commands"`
+	Inputs       []string              `yaml:"inputs"`
+	Timeout      string                `yaml:"timeout"`
+}
+
+// IsComponentManifest checks whether the path is a structured component
+// manifest (as opposed to a line-based Dependencies manifest)
+func IsComponentManifest(path string) bool {
+	ext := filepath.Ext(path)
+
+	return ext == ".yaml" || ext == ".yml"
+}
+
+func readComponentManifest(path string) (Component, []int, []error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return Component{}, nil, []error{fmt.Errorf("cannot open component manifest %s: %s", path, err)}
+	}
+
+	var manifest componentManifest
+	if err := yaml.Unmarshal(content, &manifest); err != nil {
+		return Component{}, nil, []error{fmt.Errorf("cannot read component manifest %s: %s", path, err)}
+	}
+
+	dir, _ := filepath.Split(path)
+	component := Component{
+		Name:         strings.TrimRight(dir, "/"),
+		Dependencies: make([]Dependency, 0, len(manifest.Dependencies)),
+		Owners:       manifest.Owners,
+		Tags:         manifest.Tags,
+		Commands:     manifest.Commands,
+		Inputs:       manifest.Inputs,
+	}
+
+	lines := make([]int, 0, len(manifest.Dependencies))
+	for _, d := range manifest.Dependencies {
+		// comment or blank string
+		if d.Name == "" {
+			continue
+		}
+
+		component.Dependencies = append(component.Dependencies, d.Dependency)
+		lines = append(lines, d.line)
+	}
+
+	if manifest.Timeout != "" {
+		timeout, err := time.ParseDuration(manifest.Timeout)
+		if err != nil {
+			return Component{}, nil, []error{fmt.Errorf("bad timeout in component manifest %s: %s", path, err)}
+		}
+
+		component.Timeout = timeout
+	}
+
+	return component, lines, nil
+}
+
+// ReadComponentManifest reads a single structured component manifest file
+// and returns the component or validation errors
+func ReadComponentManifest(path string) (Component, []error) {
+	component, _, errs := readComponentManifest(path)
+
+	return component, errs
+}
@@ -0,0 +1,208 @@
+package manifests
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// goModule is a Go module found in the repository
+type goModule struct {
+	path string // module path as declared in go.mod
+	dir  string // directory containing the go.mod file
+}
+
+func readGoModule(path string) (goModule, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return goModule{}, fmt.Errorf("cannot open go module %s: %s", path, err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "module ") && !strings.HasPrefix(line, "module\t") {
+			continue
+		}
+
+		modulePath := strings.TrimSpace(strings.TrimPrefix(line, "module"))
+		if unquoted, err := strconv.Unquote(modulePath); err == nil {
+			modulePath = unquoted
+		}
+
+		return goModule{modulePath, filepath.Dir(path)}, nil
+	}
+
+	if err := scanner.Err(); err != nil {
+		return goModule{}, fmt.Errorf("cannot read go module %s: %s", path, err)
+	}
+
+	return goModule{}, fmt.Errorf("no module declaration in %s", path)
+}
+
+// importDir resolves an import path to a directory using the known modules,
+// preferring the most specific module. Imports outside of the known modules
+// (standard library, third party) resolve to false.
+func importDir(modules []goModule, importPath string) (string, bool) {
+	best := -1
+	for i, m := range modules {
+		if importPath != m.path && !strings.HasPrefix(importPath, m.path+"/") {
+			continue
+		}
+
+		if best < 0 || len(m.path) > len(modules[best].path) {
+			best = i
+		}
+	}
+
+	if best < 0 {
+		return "", false
+	}
+
+	rest := strings.TrimPrefix(importPath, modules[best].path)
+	return filepath.ToSlash(filepath.Join(modules[best].dir, rest)), true
+}
+
+// owningComponent finds the component with the longest name which contains
+// the directory
+func owningComponent(components []string, dir string) (string, bool) {
+	owner := ""
+	for _, c := range components {
+		if dir != c && !strings.HasPrefix(dir, c+"/") {
+			continue
+		}
+
+		if len(c) > len(owner) {
+			owner = c
+		}
+	}
+
+	return owner, owner != ""
+}
+
+// goFiles lists Go source files belonging to a component, skipping directories
+// of other (nested) components, vendored code and test data
+func goFiles(component string, components map[string]bool) ([]string, error) {
+	files := []string{}
+
+	// components declared without a directory have no Go code
+	if _, err := os.Stat(component); errors.Is(err, fs.ErrNotExist) {
+		return files, nil
+	}
+
+	err := filepath.WalkDir(component, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		slashed := filepath.ToSlash(path)
+		if d.IsDir() {
+			name := d.Name()
+			if slashed != component && (components[slashed] || name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".")) {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if strings.HasSuffix(path, ".go") {
+			files = append(files, path)
+		}
+
+		return nil
+	})
+
+	return files, err
+}
+
+// ReadGoImports infers weak dependencies between components from the import
+// statements in their Go source files. Import paths are resolved to directories
+// using the go.mod files at modulePaths and then mapped to the component owning
+// the directory.
+// It returns the components which contain Go code and the inferred dependencies
+// of every component.
+func ReadGoImports(components []string, modulePaths []string) ([]string, Dependencies, []error) {
+	errors := []error{}
+
+	modules := make([]goModule, 0, len(modulePaths))
+	for _, path := range modulePaths {
+		module, err := readGoModule(path)
+		if err != nil {
+			errors = append(errors, err)
+			continue
+		}
+
+		modules = append(modules, module)
+	}
+
+	isComponent := make(map[string]bool, len(components))
+	for _, c := range components {
+		isComponent[c] = true
+	}
+
+	goComponents := []string{}
+	dependencies := make(map[string][]Dependency, len(components))
+	fset := token.NewFileSet()
+
+	for _, component := range components {
+		dependencies[component] = []Dependency{}
+
+		files, err := goFiles(component, isComponent)
+		if err != nil {
+			errors = append(errors, fmt.Errorf("cannot list Go files of '%s': %s", component, err))
+			continue
+		}
+
+		if len(files) > 0 {
+			goComponents = append(goComponents, component)
+		}
+
+		imported := map[string]bool{}
+		for _, file := range files {
+			ast, err := parser.ParseFile(fset, file, nil, parser.ImportsOnly)
+			if err != nil {
+				errors = append(errors, fmt.Errorf("cannot parse imports: %s", err))
+				continue
+			}
+
+			for _, spec := range ast.Imports {
+				importPath, _ := strconv.Unquote(spec.Path.Value)
+
+				dir, ok := importDir(modules, importPath)
+				if !ok {
+					continue
+				}
+
+				owner, ok := owningComponent(components, dir)
+				if ok && owner != component {
+					imported[owner] = true
+				}
+			}
+		}
+
+		names := make([]string, 0, len(imported))
+		for name := range imported {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			dependencies[component] = append(dependencies[component], Dependency{name, Weak})
+		}
+	}
+
+	if len(errors) > 0 {
+		return nil, Dependencies{}, errors
+	}
+
+	return goComponents, Dependencies{dependencies}, nil
+}
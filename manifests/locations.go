@@ -0,0 +1,98 @@
+package manifests
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Location points at a line of a manifest file
+type Location struct {
+	File string
+	Line int
+}
+
+func (l Location) String() string {
+	return fmt.Sprintf("%s:%d", l.File, l.Line)
+}
+
+// Locations maps a component and a name of its dependency to the location
+// in the manifests where the dependency is declared
+type Locations map[string]map[string]Location
+
+// Find returns the location of the declaration of a component's dependency
+func (l Locations) Find(component string, dependency string) (Location, bool) {
+	location, ok := l[component][dependency]
+
+	return location, ok
+}
+
+func (l Locations) add(component string, dependency string, location Location) {
+	if _, ok := l[component]; !ok {
+		l[component] = make(map[string]Location)
+	}
+
+	if _, ok := l[component][dependency]; !ok {
+		l[component][dependency] = location
+	}
+}
+
+// Locate finds the lines declaring dependencies in the manifests at manifestPaths
+func Locate(manifestPaths []string) (Locations, []error) {
+	locations := make(Locations, len(manifestPaths))
+	errors := []error{}
+
+	for _, path := range manifestPaths {
+		var component string
+		var deps []Dependency
+		var lines []int
+		var errs []error
+
+		if IsComponentManifest(path) {
+			var c Component
+			c, lines, errs = readComponentManifest(path)
+			component, deps = c.Name, c.Dependencies
+		} else {
+			component, deps, lines, errs = readManifestLines(path)
+		}
+
+		if errs != nil {
+			errors = append(errors, errs...)
+			continue
+		}
+
+		for i, dep := range deps {
+			locations.add(component, dep.Name, Location{path, lines[i]})
+		}
+	}
+
+	if len(errors) > 0 {
+		return nil, errors
+	}
+
+	return locations, nil
+}
+
+// LocateInRepoManifest finds the lines declaring dependencies in a full
+// repository manifest read from file
+func LocateInRepoManifest(file string, manifest string) Locations {
+	locations := make(Locations)
+
+	for i, line := range strings.Split(manifest, "\n") {
+		parts := strings.Split(strings.TrimSpace(line), ":")
+		if len(parts) != 2 || strings.HasPrefix(parts[0], "#") {
+			continue
+		}
+
+		component := strings.TrimSpace(parts[0])
+		for _, d := range strings.Split(parts[1], ",") {
+			dep, _ := readDependency(d)
+			if dep.Name == "" {
+				continue
+			}
+
+			locations.add(component, dep.Name, Location{file, i + 1})
+		}
+	}
+
+	return locations
+}
@@ -61,22 +61,24 @@ func readDependency(line string) (Dependency, error) {
 	return Dependency{dep, Weak}, nil
 }
 
-// ReadManifest reads a single manifest file and returns the dependency list
-// or validation errors
-func ReadManifest(path string) (string, []Dependency, []error) {
+// readManifestLines reads a single manifest file and returns the dependency list
+// along with the line number of each dependency
+func readManifestLines(path string) (string, []Dependency, []int, []error) {
 	dependencies := make([]Dependency, 0)
+	lines := make([]int, 0)
 	errors := make([]error, 0)
 
 	file, err := os.Open(path)
 	if err != nil {
-		return "", nil, []error{fmt.Errorf("cannot open dependency manifest %s: %s", path, err)}
+		return "", nil, nil, []error{fmt.Errorf("cannot open dependency manifest %s: %s", path, err)}
 	}
+	defer file.Close()
 
 	dir, _ := filepath.Split(path)
 	component := strings.TrimRight(dir, "/")
 
 	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
+	for line := 1; scanner.Scan(); line++ {
 		dep, err := readDependency(scanner.Text())
 
 		if err != nil {
@@ -90,41 +92,87 @@ func ReadManifest(path string) (string, []Dependency, []error) {
 		}
 
 		dependencies = append(dependencies, dep)
+		lines = append(lines, line)
 	}
 
 	err = scanner.Err()
 	if err != nil {
-		return "", nil, []error{fmt.Errorf("cannot read dependency manifest %s: %s", path, err)}
+		return "", nil, nil, []error{fmt.Errorf("cannot read dependency manifest %s: %s", path, err)}
 	}
 
-	return component, dependencies, nil
+	return component, dependencies, lines, nil
+}
+
+// ReadManifest reads a single manifest file and returns the dependency list
+// or validation errors
+func ReadManifest(path string) (string, []Dependency, []error) {
+	component, dependencies, _, errs := readManifestLines(path)
+
+	return component, dependencies, errs
 }
 
 // Read manifests at manifestPaths and return a graph of dependencies
 func Read(manifestPaths []string, dependOnSelf bool) ([]string, Dependencies, []error) {
+	components, dependencies, errors := ReadComponents(manifestPaths, dependOnSelf)
+	if errors != nil {
+		return nil, Dependencies{}, errors
+	}
+
+	return Names(components), dependencies, nil
+}
+
+// ReadComponents reads manifests at manifestPaths and returns the components
+// with their metadata and a graph of dependencies. Both Dependencies manifests
+// and structured component manifests (recognised by a .yaml or .yml extension)
+// are supported, but each component can only be declared once.
+func ReadComponents(manifestPaths []string, dependOnSelf bool) ([]Component, Dependencies, []error) {
 	dependencies := make(map[string][]Dependency, len(manifestPaths))
-	components := make([]string, 0)
+	components := make([]Component, 0)
+	names := make([]string, 0)
+	declaredIn := make(map[string]string, len(manifestPaths))
 	errors := []error{}
 
 	for _, manifest := range manifestPaths {
-		component, deps, err := ReadManifest(manifest)
-		if err != nil {
-			errors = append(errors, err...)
+		var component Component
+
+		if IsComponentManifest(manifest) {
+			c, err := ReadComponentManifest(manifest)
+			if err != nil {
+				errors = append(errors, err...)
+				continue
+			}
+
+			component = c
+		} else {
+			name, deps, err := ReadManifest(manifest)
+			if err != nil {
+				errors = append(errors, err...)
+				continue
+			}
+
+			component = Component{Name: name, Dependencies: deps}
+		}
+
+		if other, ok := declaredIn[component.Name]; ok {
+			errors = append(errors, fmt.Errorf("component '%s' is declared in both %s and %s", component.Name, other, manifest))
 			continue
 		}
+		declaredIn[component.Name] = manifest
 
+		deps := component.Dependencies
 		if dependOnSelf {
-			deps = append([]Dependency{Dependency{component, Weak}}, deps...)
+			deps = append([]Dependency{Dependency{component.Name, Weak}}, deps...)
 		}
 
 		components = append(components, component)
-		dependencies[component] = deps
+		names = append(names, component.Name)
+		dependencies[component.Name] = deps
 	}
 
 	// validate dependencies
 	for manifest, deps := range dependencies {
 		for _, dep := range deps {
-			if !validDependency(components, dep) {
+			if !validDependency(names, dep) {
 				errors = append(errors, fmt.Errorf("unknown dependency '%s' of '%s'", dep.Name, manifest))
 			}
 		}
@@ -137,6 +185,16 @@ func Read(manifestPaths []string, dependOnSelf bool) ([]string, Dependencies, []
 	return components, Dependencies{dependencies}, nil
 }
 
+// Names returns the names of components
+func Names(components []Component) []string {
+	names := make([]string, 0, len(components))
+	for _, c := range components {
+		names = append(names, c.Name)
+	}
+
+	return names
+}
+
 // ReadRepoManifest reads a full repository manifest as produced by monobuild print --full
 func ReadRepoManifest(manifest string, dependOnSelf bool) ([]string, Dependencies, []error) {
 	lines := strings.Split(manifest, "\n")
@@ -228,3 +286,32 @@ func (d Dependencies) AsGraph() graph.Graph {
 
 	return graph.New(result)
 }
+
+// Merge returns the dependencies declared in either d or other. When both
+// declare the same dependency of a component, the stronger kind is kept.
+func (d Dependencies) Merge(other Dependencies) Dependencies {
+	result := make(map[string][]Dependency, len(d.deps))
+
+	for c, ds := range d.deps {
+		result[c] = append([]Dependency{}, ds...)
+	}
+
+	for c, ds := range other.deps {
+	Outer:
+		for _, o := range ds {
+			for i, existing := range result[c] {
+				if existing.Name == o.Name {
+					if o.Kind > existing.Kind {
+						result[c][i].Kind = o.Kind
+					}
+
+					continue Outer
+				}
+			}
+
+			result[c] = append(result[c], o)
+		}
+	}
+
+	return Dependencies{result}
+}
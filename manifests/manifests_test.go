@@ -3,10 +3,12 @@ package manifests
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"reflect"
 	"sort"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/bmatcuk/doublestar"
 )
@@ -327,3 +329,202 @@ func TestReadRepoManifest(t *testing.T) {
 		})
 	}
 }
+
+func Test_ReadGoImports(t *testing.T) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		panic(fmt.Errorf("Error finding current directory: %s", err))
+	}
+
+	chdir("../test/fixtures/go-imports")
+	defer chdir(cwd)
+
+	// infra has no directory, e.g. when declared in a repository manifest
+	components := []string{"app1", "infra", "libs/lib1", "libs/lib2", "libs/lib3"}
+	want := []string{"app1", "libs/lib1", "libs/lib2", "libs/lib3"}
+	want1 := Dependencies{deps: map[string][]Dependency{
+		"app1":      []Dependency{{"libs/lib1", Weak}, {"libs/lib3", Weak}},
+		"infra":     []Dependency{},
+		"libs/lib1": []Dependency{},
+		"libs/lib2": []Dependency{},
+		"libs/lib3": []Dependency{{"libs/lib2", Weak}},
+	}}
+
+	got, got1, errs := ReadGoImports(components, []string{"go.mod"})
+	if errs != nil {
+		t.Fatalf("ReadGoImports() errors = %v", errs)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadGoImports() got = %#v, want %#v", got, want)
+	}
+	if !reflect.DeepEqual(got1, want1) {
+		t.Errorf("ReadGoImports() got1 = %#v, want %#v", got1, want1)
+	}
+}
+
+func Test_readGoModule(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+		wantErr bool
+	}{
+		{"reads a module", "module example.com/mono\n\ngo 1.17\n", "example.com/mono", false},
+		{"reads a module separated with a tab", "module\texample.com/mono\n", "example.com/mono", false},
+		{"reads a quoted module", "module \"example.com/mono\"\n", "example.com/mono", false},
+		{"skips lines only starting with module", "modules are declared below\nmodule example.com/mono\n", "example.com/mono", false},
+		{"fails without a module", "go 1.17\n", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "go.mod")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := readGoModule(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("readGoModule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got.path != tt.want {
+				t.Errorf("readGoModule() = %v, want %v", got.path, tt.want)
+			}
+		})
+	}
+}
+
+func TestDependencies_Merge(t *testing.T) {
+	tests := []struct {
+		name  string
+		deps  Dependencies
+		other Dependencies
+		want  Dependencies
+	}{
+		{
+			"merges empty dependencies",
+			Dependencies{deps: map[string][]Dependency{}},
+			Dependencies{deps: map[string][]Dependency{}},
+			Dependencies{deps: map[string][]Dependency{}},
+		},
+		{
+			"adds new dependencies",
+			Dependencies{deps: map[string][]Dependency{
+				"a": []Dependency{{"b", Weak}},
+				"b": []Dependency{},
+			}},
+			Dependencies{deps: map[string][]Dependency{
+				"a": []Dependency{{"c", Weak}},
+				"c": []Dependency{{"b", Weak}},
+			}},
+			Dependencies{deps: map[string][]Dependency{
+				"a": []Dependency{{"b", Weak}, {"c", Weak}},
+				"b": []Dependency{},
+				"c": []Dependency{{"b", Weak}},
+			}},
+		},
+		{
+			"keeps the stronger kind of a duplicate dependency",
+			Dependencies{deps: map[string][]Dependency{
+				"a": []Dependency{{"b", Weak}, {"c", Strong}},
+			}},
+			Dependencies{deps: map[string][]Dependency{
+				"a": []Dependency{{"b", Strong}, {"c", Weak}, {"d", Weak}},
+			}},
+			Dependencies{deps: map[string][]Dependency{
+				"a": []Dependency{{"b", Strong}, {"c", Strong}, {"d", Weak}},
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.deps.Merge(tt.other); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Dependencies.Merge() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_ReadComponents(t *testing.T) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		panic(fmt.Errorf("Error finding current directory: %s", err))
+	}
+
+	chdir("../test/fixtures/component-manifests")
+	defer chdir(cwd)
+
+	paths := []string{"app1/Dependencies", "libs/lib1/monobuild.yaml", "stack1/monobuild.yaml"}
+	want := []Component{
+		{
+			Name:         "app1",
+			Dependencies: []Dependency{{"libs/lib1", Weak}},
+		},
+		{
+			Name:         "libs/lib1",
+			Dependencies: []Dependency{},
+			Owners:       []string{"platform"},
+			Tags:         []string{"library", "lang:go"},
+			Commands:     Commands{Build: "go build ./...", Test: "go test ./..."},
+			Inputs:       []string{"**/*.go", "go.mod"},
+		},
+		{
+			Name:         "stack1",
+			Dependencies: []Dependency{{"app1", Strong}, {"libs/lib1", Weak}},
+			Owners:       []string{"payments"},
+			Tags:         []string{"service", "team:payments"},
+			Commands:     Commands{Build: "make build", Deploy: "make deploy"},
+			Timeout:      10 * time.Minute,
+		},
+	}
+	want1 := Dependencies{deps: map[string][]Dependency{
+		"app1":      []Dependency{{"libs/lib1", Weak}},
+		"libs/lib1": []Dependency{},
+		"stack1":    []Dependency{{"app1", Strong}, {"libs/lib1", Weak}},
+	}}
+
+	got, got1, errs := ReadComponents(paths, false)
+	if errs != nil {
+		t.Fatalf("ReadComponents() errors = %v", errs)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadComponents() got = %#v, want %#v", got, want)
+	}
+	if !reflect.DeepEqual(got1, want1) {
+		t.Errorf("ReadComponents() got1 = %#v, want %#v", got1, want1)
+	}
+
+	_, _, errs = ReadComponents(append(paths, "app1/Dependencies"), false)
+	if errs == nil {
+		t.Errorf("ReadComponents() accepted a component declared twice")
+	}
+}
+
+func Test_Locate(t *testing.T) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		panic(fmt.Errorf("Error finding current directory: %s", err))
+	}
+
+	chdir("../test/fixtures/component-manifests")
+	defer chdir(cwd)
+
+	want := Locations{
+		"app1": {"libs/lib1": {"app1/Dependencies", 1}},
+		"stack1": {
+			"app1":      {"stack1/monobuild.yaml", 3},
+			"libs/lib1": {"stack1/monobuild.yaml", 4},
+		},
+	}
+
+	got, errs := Locate([]string{"app1/Dependencies", "libs/lib1/monobuild.yaml", "stack1/monobuild.yaml"})
+	if errs != nil {
+		t.Fatalf("Locate() errors = %v", errs)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Locate() = %#v, want %#v", got, want)
+	}
+}
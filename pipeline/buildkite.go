@@ -0,0 +1,74 @@
+package pipeline
+
+import (
+	"fmt"
+	"math"
+)
+
+type buildkiteDependency struct {
+	Step         string `yaml:"step"`
+	AllowFailure bool   `yaml:"allow_failure,omitempty"`
+}
+
+type buildkiteStep struct {
+	Label            string                `yaml:"label"`
+	Key              string                `yaml:"key,omitempty"`
+	Commands         []string              `yaml:"commands"`
+	Env              map[string]string     `yaml:"env,omitempty"`
+	DependsOn        []buildkiteDependency `yaml:"depends_on,omitempty"`
+	TimeoutInMinutes int                   `yaml:"timeout_in_minutes,omitempty"`
+}
+
+type buildkitePipeline struct {
+	Steps []buildkiteStep `yaml:"steps"`
+}
+
+// Buildkite returns a Buildkite pipeline with a step for each component, to be
+// uploaded with 'buildkite-agent pipeline upload'. Steps depend on the steps of
+// their strong dependencies, and on the steps of their weak dependencies
+// allowing them to fail. Steps get the component and its build command in the
+// COMPONENT and BUILD_COMMAND environment variables and by default run the
+// build command in the component's directory, the script of the job template
+// replaces the default commands.
+func Buildkite(p Pipeline) (string, error) {
+	pipeline := buildkitePipeline{Steps: make([]buildkiteStep, 0, len(p.Jobs))}
+	ids := p.ids()
+
+	for _, j := range p.Jobs {
+		commands := p.Template.Script
+		if len(commands) < 1 {
+			commands = []string{fmt.Sprintf("cd %s", j.Component), j.Command}
+		}
+
+		step := buildkiteStep{
+			Label:            j.Component,
+			Key:              j.ID,
+			Commands:         commands,
+			Env:              map[string]string{"COMPONENT": j.Component, "BUILD_COMMAND": j.Command},
+			TimeoutInMinutes: int(math.Ceil(j.Timeout.Minutes())),
+		}
+
+		for _, n := range j.Needs {
+			step.DependsOn = append(step.DependsOn, buildkiteDependency{Step: ids[n]})
+		}
+		for _, u := range j.Uses {
+			step.DependsOn = append(step.DependsOn, buildkiteDependency{Step: ids[u], AllowFailure: true})
+		}
+
+		pipeline.Steps = append(pipeline.Steps, step)
+	}
+
+	if len(pipeline.Steps) < 1 {
+		pipeline.Steps = append(pipeline.Steps, buildkiteStep{
+			Label:    "nothing to build",
+			Commands: []string{"echo 'No components affected'"},
+		})
+	}
+
+	result, err := marshalYAML(pipeline)
+	if err != nil {
+		return "", fmt.Errorf("cannot generate Buildkite pipeline: %s", err)
+	}
+
+	return result, nil
+}
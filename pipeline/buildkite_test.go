@@ -0,0 +1,92 @@
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/charypar/monobuild/config"
+)
+
+func TestBuildkite(t *testing.T) {
+	templated := examplePipeline
+	templated.Jobs = examplePipeline.Jobs[1:2]
+	templated.Template = config.JobTemplate{Script: []string{"cd $COMPONENT", "eval \"$BUILD_COMMAND\""}}
+
+	tests := []struct {
+		name     string
+		pipeline Pipeline
+		want     string
+	}{
+		{
+			"generates steps with dependencies",
+			examplePipeline,
+			`steps:
+  - label: apps/app1
+    key: apps-app1
+    commands:
+      - cd apps/app1
+      - go build ./...
+    env:
+      BUILD_COMMAND: go build ./...
+      COMPONENT: apps/app1
+    depends_on:
+      - step: libs-lib1
+    timeout_in_minutes: 2
+  - label: libs/lib1
+    key: libs-lib1
+    commands:
+      - cd libs/lib1
+      - make build
+    env:
+      BUILD_COMMAND: make build
+      COMPONENT: libs/lib1
+  - label: stack1
+    key: stack1
+    commands:
+      - cd stack1
+      - make build
+    env:
+      BUILD_COMMAND: make build
+      COMPONENT: stack1
+    depends_on:
+      - step: apps-app1
+      - step: libs-lib1
+        allow_failure: true
+`,
+		},
+		{
+			"uses the job template",
+			templated,
+			`steps:
+  - label: libs/lib1
+    key: libs-lib1
+    commands:
+      - cd $COMPONENT
+      - eval "$BUILD_COMMAND"
+    env:
+      BUILD_COMMAND: make build
+      COMPONENT: libs/lib1
+`,
+		},
+		{
+			"generates a placeholder step for an empty pipeline",
+			Pipeline{Jobs: []Job{}},
+			`steps:
+  - label: nothing to build
+    commands:
+      - echo 'No components affected'
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Buildkite(tt.pipeline)
+			if err != nil {
+				t.Fatalf("Buildkite() error = %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("Buildkite() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
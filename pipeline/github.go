@@ -0,0 +1,113 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+)
+
+type githubMatrixEntry struct {
+	Component string `json:"component"`
+	ID        string `json:"id"`
+	Build     string `json:"build"`
+}
+
+type githubMatrix struct {
+	Include []githubMatrixEntry `json:"include"`
+}
+
+// GitHubMatrix returns a job matrix for GitHub Actions with an entry for each
+// job, to be used as 'strategy.matrix: ${{ fromJSON(...) }}'. The matrix
+// doesn't express the order of the jobs.
+func GitHubMatrix(p Pipeline) (string, error) {
+	matrix := githubMatrix{Include: make([]githubMatrixEntry, 0, len(p.Jobs))}
+	for _, j := range p.Jobs {
+		matrix.Include = append(matrix.Include, githubMatrixEntry{j.Component, j.ID, j.Command})
+	}
+
+	bytes, err := json.Marshal(matrix)
+	if err != nil {
+		return "", fmt.Errorf("cannot generate GitHub matrix: %s", err)
+	}
+
+	return string(bytes) + "\n", nil
+}
+
+type githubStep struct {
+	Name             string            `yaml:"name,omitempty"`
+	Uses             string            `yaml:"uses,omitempty"`
+	Run              string            `yaml:"run,omitempty"`
+	WorkingDirectory string            `yaml:"working-directory,omitempty"`
+	Env              map[string]string `yaml:"env,omitempty"`
+}
+
+type githubJob struct {
+	Name           string       `yaml:"name"`
+	Needs          []string     `yaml:"needs,omitempty"`
+	RunsOn         string       `yaml:"runs-on"`
+	TimeoutMinutes int          `yaml:"timeout-minutes,omitempty"`
+	Steps          []githubStep `yaml:"steps"`
+}
+
+type githubWorkflow struct {
+	Name string               `yaml:"name"`
+	On   []string             `yaml:"on"`
+	Jobs map[string]githubJob `yaml:"jobs"`
+}
+
+// GitHubWorkflow returns a GitHub Actions workflow with a job for each
+// component. Jobs need the jobs of their strong dependencies. Jobs get the
+// component and its build command in the COMPONENT and BUILD_COMMAND
+// environment variables and by default run the build command in the
+// component's directory, the script of the job template replaces it. The
+// workflow runs on the pipeline's trigger events, on push by default.
+func GitHubWorkflow(p Pipeline) (string, error) {
+	on := p.Trigger
+	if len(on) < 1 {
+		on = []string{"push"}
+	}
+
+	workflow := githubWorkflow{
+		Name: p.Name,
+		On:   on,
+		Jobs: make(map[string]githubJob, len(p.Jobs)),
+	}
+
+	ids := p.ids()
+	for _, j := range p.Jobs {
+		needs := make([]string, 0, len(j.Needs))
+		for _, n := range j.Needs {
+			needs = append(needs, ids[n])
+		}
+
+		build := githubStep{
+			Name: "Build",
+			Run:  j.Command,
+			Env:  map[string]string{"COMPONENT": j.Component, "BUILD_COMMAND": j.Command},
+		}
+		if len(p.Template.Script) > 0 {
+			build.Run = strings.Join(p.Template.Script, "\n")
+		} else {
+			build.WorkingDirectory = j.Component
+		}
+
+		workflow.Jobs[j.ID] = githubJob{
+			Name:           j.Component,
+			Needs:          needs,
+			RunsOn:         "ubuntu-latest",
+			TimeoutMinutes: int(math.Ceil(j.Timeout.Minutes())),
+			Steps: []githubStep{
+				{Uses: "actions/checkout@v4"},
+				build,
+			},
+		}
+	}
+
+	result, err := marshalYAML(workflow)
+	if err != nil {
+		return "", fmt.Errorf("cannot generate GitHub workflow: %s", err)
+	}
+
+	return result, nil
+}
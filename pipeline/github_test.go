@@ -0,0 +1,126 @@
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/charypar/monobuild/config"
+)
+
+func TestGitHubMatrix(t *testing.T) {
+	want := `{"include":[{"component":"apps/app1","id":"apps-app1","build":"go build ./..."},{"component":"libs/lib1","id":"libs-lib1","build":"make build"},{"component":"stack1","id":"stack1","build":"make build"}]}
+`
+
+	got, err := GitHubMatrix(examplePipeline)
+	if err != nil {
+		t.Fatalf("GitHubMatrix() error = %v", err)
+	}
+
+	if got != want {
+		t.Errorf("GitHubMatrix() = %v, want %v", got, want)
+	}
+
+	empty, _ := GitHubMatrix(Pipeline{})
+	if empty != "{\"include\":[]}\n" {
+		t.Errorf("GitHubMatrix() = %v, want an empty matrix", empty)
+	}
+}
+
+func TestGitHubWorkflow(t *testing.T) {
+	want := `name: ci
+"on":
+  - push
+jobs:
+  apps-app1:
+    name: apps/app1
+    needs:
+      - libs-lib1
+    runs-on: ubuntu-latest
+    timeout-minutes: 2
+    steps:
+      - uses: actions/checkout@v4
+      - name: Build
+        run: go build ./...
+        working-directory: apps/app1
+        env:
+          BUILD_COMMAND: go build ./...
+          COMPONENT: apps/app1
+  libs-lib1:
+    name: libs/lib1
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+      - name: Build
+        run: make build
+        working-directory: libs/lib1
+        env:
+          BUILD_COMMAND: make build
+          COMPONENT: libs/lib1
+  stack1:
+    name: stack1
+    needs:
+      - apps-app1
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+      - name: Build
+        run: make build
+        working-directory: stack1
+        env:
+          BUILD_COMMAND: make build
+          COMPONENT: stack1
+`
+
+	got, err := GitHubWorkflow(examplePipeline)
+	if err != nil {
+		t.Fatalf("GitHubWorkflow() error = %v", err)
+	}
+
+	if got != want {
+		t.Errorf("GitHubWorkflow() = %v, want %v", got, want)
+	}
+
+	templated := examplePipeline
+	templated.Jobs = examplePipeline.Jobs[1:2]
+	templated.Template = config.JobTemplate{Script: []string{"cd $COMPONENT", "eval \"$BUILD_COMMAND\""}}
+	got, err = GitHubWorkflow(templated)
+	if err != nil {
+		t.Fatalf("GitHubWorkflow() error = %v", err)
+	}
+
+	want = `name: ci
+"on":
+  - push
+jobs:
+  libs-lib1:
+    name: libs/lib1
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+      - name: Build
+        run: |-
+          cd $COMPONENT
+          eval "$BUILD_COMMAND"
+        env:
+          BUILD_COMMAND: make build
+          COMPONENT: libs/lib1
+`
+	if got != want {
+		t.Errorf("GitHubWorkflow() = %v, want %v", got, want)
+	}
+
+	called := Pipeline{Name: "ci", Jobs: []Job{}, Trigger: []string{"workflow_call", "workflow_dispatch"}}
+	got, err = GitHubWorkflow(called)
+	if err != nil {
+		t.Fatalf("GitHubWorkflow() error = %v", err)
+	}
+
+	want = `name: ci
+"on":
+  - workflow_call
+  - workflow_dispatch
+jobs: {}
+`
+	if got != want {
+		t.Errorf("GitHubWorkflow() = %v, want %v", got, want)
+	}
+}
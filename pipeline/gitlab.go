@@ -0,0 +1,84 @@
+package pipeline
+
+import (
+	"fmt"
+	"math"
+)
+
+type gitlabJob struct {
+	Stage     string            `yaml:"stage"`
+	Image     string            `yaml:"image,omitempty"`
+	Tags      []string          `yaml:"tags,omitempty"`
+	Needs     []string          `yaml:"needs"`
+	Variables map[string]string `yaml:"variables,omitempty"`
+	Timeout   string            `yaml:"timeout,omitempty"`
+	Script    []string          `yaml:"script"`
+}
+
+func gitlabStage(stage int) string {
+	return fmt.Sprintf("build-%d", stage+1)
+}
+
+func gitlabJobName(component string) string {
+	return "build:" + component
+}
+
+// GitLabCI returns a GitLab CI child pipeline with a job for each component.
+// Jobs need the jobs of their strong dependencies, other jobs start right away.
+// Jobs get the component and its build command in the COMPONENT and
+// BUILD_COMMAND variables and by default run the build command in the
+// component's directory. The image, tags and script of the jobs can be set
+// by the job template.
+func GitLabCI(p Pipeline) (string, error) {
+	stages := make([]string, 0, p.Stages)
+	for s := 0; s < p.Stages; s++ {
+		stages = append(stages, gitlabStage(s))
+	}
+
+	pipeline := orderedMap{}
+
+	if len(p.Jobs) < 1 {
+		// GitLab refuses to run a pipeline without jobs
+		pipeline = append(pipeline, keyValue{"nothing-to-build", gitlabJob{
+			Stage:  "build",
+			Needs:  []string{},
+			Script: []string{"echo 'No components affected'"},
+		}})
+		stages = []string{"build"}
+	}
+
+	pipeline = append(orderedMap{{"stages", stages}}, pipeline...)
+
+	for _, j := range p.Jobs {
+		needs := make([]string, 0, len(j.Needs))
+		for _, n := range j.Needs {
+			needs = append(needs, gitlabJobName(n))
+		}
+
+		script := p.Template.Script
+		if len(script) < 1 {
+			script = []string{fmt.Sprintf("cd %s", j.Component), j.Command}
+		}
+
+		job := gitlabJob{
+			Stage:     gitlabStage(j.Stage),
+			Image:     p.Template.Image,
+			Tags:      p.Template.Tags,
+			Needs:     needs,
+			Variables: map[string]string{"COMPONENT": j.Component, "BUILD_COMMAND": j.Command},
+			Script:    script,
+		}
+		if j.Timeout > 0 {
+			job.Timeout = fmt.Sprintf("%d minutes", int(math.Ceil(j.Timeout.Minutes())))
+		}
+
+		pipeline = append(pipeline, keyValue{gitlabJobName(j.Component), job})
+	}
+
+	result, err := marshalYAML(pipeline)
+	if err != nil {
+		return "", fmt.Errorf("cannot generate GitLab CI pipeline: %s", err)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,107 @@
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/charypar/monobuild/config"
+)
+
+func TestGitLabCI(t *testing.T) {
+	templated := examplePipeline
+	templated.Jobs = examplePipeline.Jobs[1:2]
+	templated.Stages = 1
+	templated.Template = config.JobTemplate{
+		Image:  "golang:1.17",
+		Tags:   []string{"docker"},
+		Script: []string{"cd $COMPONENT", "eval \"$BUILD_COMMAND\""},
+	}
+
+	tests := []struct {
+		name     string
+		pipeline Pipeline
+		want     string
+	}{
+		{
+			"generates jobs with needs",
+			examplePipeline,
+			`stages:
+  - build-1
+  - build-2
+  - build-3
+build:apps/app1:
+  stage: build-2
+  needs:
+    - build:libs/lib1
+  variables:
+    BUILD_COMMAND: go build ./...
+    COMPONENT: apps/app1
+  timeout: 2 minutes
+  script:
+    - cd apps/app1
+    - go build ./...
+build:libs/lib1:
+  stage: build-1
+  needs: []
+  variables:
+    BUILD_COMMAND: make build
+    COMPONENT: libs/lib1
+  script:
+    - cd libs/lib1
+    - make build
+build:stack1:
+  stage: build-3
+  needs:
+    - build:apps/app1
+  variables:
+    BUILD_COMMAND: make build
+    COMPONENT: stack1
+  script:
+    - cd stack1
+    - make build
+`,
+		},
+		{
+			"uses the job template",
+			templated,
+			`stages:
+  - build-1
+build:libs/lib1:
+  stage: build-1
+  image: golang:1.17
+  tags:
+    - docker
+  needs: []
+  variables:
+    BUILD_COMMAND: make build
+    COMPONENT: libs/lib1
+  script:
+    - cd $COMPONENT
+    - eval "$BUILD_COMMAND"
+`,
+		},
+		{
+			"generates a placeholder job for an empty pipeline",
+			Pipeline{Jobs: []Job{}},
+			`stages:
+  - build
+nothing-to-build:
+  stage: build
+  needs: []
+  script:
+    - echo 'No components affected'
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GitLabCI(tt.pipeline)
+			if err != nil {
+				t.Fatalf("GitLabCI() error = %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("GitLabCI() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
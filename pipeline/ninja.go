@@ -0,0 +1,82 @@
+package pipeline
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var ninjaPathEscapes = strings.NewReplacer("$", "$$", " ", "$ ", ":", "$:")
+
+var shellSafe = regexp.MustCompile("^[A-Za-z0-9_./-]+$")
+
+// ninjaPath escapes a path in a Ninja build statement
+func ninjaPath(path string) string {
+	return ninjaPathEscapes.Replace(path)
+}
+
+// ninjaValue escapes the value of a Ninja variable
+func ninjaValue(value string) string {
+	return strings.ReplaceAll(value, "$", "$$")
+}
+
+// shellQuote quotes a word for sh, unless it's safe as it is
+func shellQuote(word string) string {
+	if shellSafe.MatchString(word) {
+		return word
+	}
+
+	return "'" + strings.ReplaceAll(word, "'", `'\''`) + "'"
+}
+
+// Ninja returns a Ninja build file with a phony target for each component,
+// which runs the build command in the component's directory and records the
+// success in a stamp file in the build directory. The files of components are
+// implicit inputs of their builds, so finished builds run again once the files
+// change. Builds have order-only dependencies on the builds of their strong
+// dependencies, which order them but don't make them run again, weak
+// dependencies don't impose any order and are left out.
+func Ninja(p Pipeline) (string, error) {
+	var b strings.Builder
+	ids := p.ids()
+
+	stamp := func(component string) string {
+		return fmt.Sprintf("$builddir/%s.stamp", ids[component])
+	}
+
+	fmt.Fprintf(&b, "# %s, generated by monobuild\n\n", p.Name)
+	b.WriteString("builddir = .monobuild\n\n")
+	b.WriteString("rule build\n")
+	b.WriteString("  command = (cd $dir && $build_command) && touch $out\n")
+	b.WriteString("  description = Building $component\n")
+
+	targets := make([]string, 0, len(p.Jobs))
+	for _, j := range p.Jobs {
+		fmt.Fprintf(&b, "\nbuild %s: build", stamp(j.Component))
+		if len(j.Files) > 0 {
+			b.WriteString(" |")
+			for _, f := range j.Files {
+				fmt.Fprintf(&b, " %s", ninjaPath(f))
+			}
+		}
+		if len(j.Needs) > 0 {
+			b.WriteString(" ||")
+			for _, n := range j.Needs {
+				fmt.Fprintf(&b, " %s", stamp(n))
+			}
+		}
+		b.WriteString("\n")
+
+		fmt.Fprintf(&b, "  component = %s\n", ninjaValue(j.Component))
+		fmt.Fprintf(&b, "  dir = %s\n", ninjaValue(shellQuote(j.Component)))
+		fmt.Fprintf(&b, "  build_command = %s\n", ninjaValue(j.Command))
+		fmt.Fprintf(&b, "build %s: phony %s\n", ninjaPath(j.Component), stamp(j.Component))
+
+		targets = append(targets, ninjaPath(j.Component))
+	}
+
+	fmt.Fprintf(&b, "\nbuild all: phony %s\n", strings.Join(targets, " "))
+	b.WriteString("default all\n")
+
+	return b.String(), nil
+}
@@ -0,0 +1,163 @@
+package pipeline
+
+import (
+	"testing"
+)
+
+func TestNinja(t *testing.T) {
+	escaped := Pipeline{
+		Name: "ci",
+		Jobs: []Job{
+			{Component: "my app", ID: "my-app", Needs: []string{}, Uses: []string{}, Command: "echo $HOME", Files: []string{"my app/main.go"}},
+		},
+		Stages: 1,
+	}
+
+	weak := Pipeline{
+		Name: "ci",
+		Jobs: []Job{
+			{Component: "app", ID: "app", Needs: []string{}, Uses: []string{"lib"}, Stage: 0, Command: "make build"},
+			{Component: "lib", ID: "lib", Needs: []string{}, Uses: []string{}, Stage: 0, Command: "make build"},
+		},
+		Stages: 1,
+	}
+
+	withFiles := examplePipeline
+	withFiles.Jobs = make([]Job, len(examplePipeline.Jobs))
+	copy(withFiles.Jobs, examplePipeline.Jobs)
+	withFiles.Jobs[0].Files = []string{"apps/app1/go.mod", "apps/app1/main.go"}
+	withFiles.Jobs[1].Files = []string{"libs/lib1/Makefile"}
+
+	tests := []struct {
+		name     string
+		pipeline Pipeline
+		want     string
+	}{
+		{
+			"generates targets with order-only strong dependencies",
+			examplePipeline,
+			`# ci, generated by monobuild
+
+builddir = .monobuild
+
+rule build
+  command = (cd $dir && $build_command) && touch $out
+  description = Building $component
+
+build $builddir/apps-app1.stamp: build || $builddir/libs-lib1.stamp
+  component = apps/app1
+  dir = apps/app1
+  build_command = go build ./...
+build apps/app1: phony $builddir/apps-app1.stamp
+
+build $builddir/libs-lib1.stamp: build
+  component = libs/lib1
+  dir = libs/lib1
+  build_command = make build
+build libs/lib1: phony $builddir/libs-lib1.stamp
+
+build $builddir/stack1.stamp: build || $builddir/apps-app1.stamp
+  component = stack1
+  dir = stack1
+  build_command = make build
+build stack1: phony $builddir/stack1.stamp
+
+build all: phony apps/app1 libs/lib1 stack1
+default all
+`,
+		},
+		{
+			"generates targets depending on files",
+			withFiles,
+			`# ci, generated by monobuild
+
+builddir = .monobuild
+
+rule build
+  command = (cd $dir && $build_command) && touch $out
+  description = Building $component
+
+build $builddir/apps-app1.stamp: build | apps/app1/go.mod apps/app1/main.go || $builddir/libs-lib1.stamp
+  component = apps/app1
+  dir = apps/app1
+  build_command = go build ./...
+build apps/app1: phony $builddir/apps-app1.stamp
+
+build $builddir/libs-lib1.stamp: build | libs/lib1/Makefile
+  component = libs/lib1
+  dir = libs/lib1
+  build_command = make build
+build libs/lib1: phony $builddir/libs-lib1.stamp
+
+build $builddir/stack1.stamp: build || $builddir/apps-app1.stamp
+  component = stack1
+  dir = stack1
+  build_command = make build
+build stack1: phony $builddir/stack1.stamp
+
+build all: phony apps/app1 libs/lib1 stack1
+default all
+`,
+		},
+		{
+			"leaves out weak dependencies",
+			weak,
+			`# ci, generated by monobuild
+
+builddir = .monobuild
+
+rule build
+  command = (cd $dir && $build_command) && touch $out
+  description = Building $component
+
+build $builddir/app.stamp: build
+  component = app
+  dir = app
+  build_command = make build
+build app: phony $builddir/app.stamp
+
+build $builddir/lib.stamp: build
+  component = lib
+  dir = lib
+  build_command = make build
+build lib: phony $builddir/lib.stamp
+
+build all: phony app lib
+default all
+`,
+		},
+		{
+			"escapes paths and commands",
+			escaped,
+			`# ci, generated by monobuild
+
+builddir = .monobuild
+
+rule build
+  command = (cd $dir && $build_command) && touch $out
+  description = Building $component
+
+build $builddir/my-app.stamp: build | my$ app/main.go
+  component = my app
+  dir = 'my app'
+  build_command = echo $$HOME
+build my$ app: phony $builddir/my-app.stamp
+
+build all: phony my$ app
+default all
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Ninja(tt.pipeline)
+			if err != nil {
+				t.Fatalf("Ninja() error = %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("Ninja() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package pipeline
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/charypar/monobuild/config"
+	"github.com/charypar/monobuild/graph"
+	"github.com/charypar/monobuild/manifests"
+	"github.com/charypar/monobuild/set"
+	"gopkg.in/yaml.v3"
+)
+
+// Options hold the defaults of generated pipelines
+type Options struct {
+	Name         string   // Name of the pipeline
+	BuildCommand string   // Build command of components which don't declare one
+	Trigger      []string // Events triggering the pipeline (GitHub Actions)
+	Template     config.JobTemplate
+}
+
+// Job is the build of a single component in a CI pipeline
+type Job struct {
+	Component string
+	ID        string   // Identifier of the job, safe to use in CI configuration
+	Needs     []string // Components whose jobs need to finish first (strong dependencies)
+	Uses      []string // Components depended on weakly, their jobs may run first but don't need to succeed
+	Stage     int      // Stage of the pipeline, jobs only need jobs in earlier stages
+	Command   string
+	Timeout   time.Duration // Timeout of the job, none if zero
+	Tags      []string
+	Files     []string // Files of the component, only known to targets which need them
+}
+
+// Pipeline is a CI pipeline building the selected components in the order
+// of the build schedule
+type Pipeline struct {
+	Name     string
+	Jobs     []Job    // Jobs sorted by component
+	Stages   int      // Number of stages
+	Trigger  []string // Events triggering the pipeline, 'push' if empty
+	Template config.JobTemplate
+}
+
+var unsafe = regexp.MustCompile("[^A-Za-z0-9_-]+")
+
+// jobIDs derives unique identifiers made of letters, digits, '-' and '_' from
+// component names, e.g. libs/lib1 becomes libs-lib1
+func jobIDs(components []string) map[string]string {
+	ids := make(map[string]string, len(components))
+	used := make(map[string]bool, len(components))
+
+	for _, c := range components {
+		base := unsafe.ReplaceAllString(c, "-")
+		if base == "" || (base[0] >= '0' && base[0] <= '9') || base[0] == '-' {
+			base = "_" + base
+		}
+
+		id := base
+		for i := 2; used[id]; i++ {
+			id = fmt.Sprintf("%s_%d", base, i)
+		}
+
+		ids[c] = id
+		used[id] = true
+	}
+
+	return ids
+}
+
+// New creates a pipeline building the selected components. Jobs need the jobs
+// of their strong dependencies in the selection.
+func New(dependencies graph.Graph, schedule graph.Graph, selection []string, components map[string]manifests.Component, opts Options) (Pipeline, error) {
+	selected := schedule.Subgraph(selection)
+	selectedDependencies := dependencies.Subgraph(selection)
+
+	stages, err := selected.Levels()
+	if err != nil {
+		return Pipeline{}, fmt.Errorf("cannot order the pipeline: %s", err)
+	}
+
+	vertices := selected.Vertices()
+	ids := jobIDs(vertices)
+	pipeline := Pipeline{Name: opts.Name, Jobs: make([]Job, 0, len(vertices)), Trigger: opts.Trigger, Template: opts.Template}
+
+	for _, c := range vertices {
+		job := Job{
+			Component: c,
+			ID:        ids[c],
+			Needs:     []string{},
+			Uses:      []string{},
+			Stage:     stages[c],
+			Command:   components[c].Commands.Build,
+			Timeout:   components[c].Timeout,
+			Tags:      components[c].Tags,
+		}
+
+		if job.Command == "" {
+			job.Command = opts.BuildCommand
+		}
+
+		job.Needs = append(job.Needs, selected.Children([]string{c})...)
+
+		needs := set.New(job.Needs)
+		for _, d := range selectedDependencies.Children([]string{c}) {
+			if !needs.Has(d) {
+				job.Uses = append(job.Uses, d)
+			}
+		}
+
+		if job.Stage+1 > pipeline.Stages {
+			pipeline.Stages = job.Stage + 1
+		}
+
+		pipeline.Jobs = append(pipeline.Jobs, job)
+	}
+
+	return pipeline, nil
+}
+
+// ids returns the job ID of each component in the pipeline
+func (p Pipeline) ids() map[string]string {
+	ids := make(map[string]string, len(p.Jobs))
+	for _, j := range p.Jobs {
+		ids[j.Component] = j.ID
+	}
+
+	return ids
+}
+
+// orderedMap is a YAML mapping keeping the order of its keys
+type orderedMap []keyValue
+
+type keyValue struct {
+	key   string
+	value interface{}
+}
+
+func (m orderedMap) MarshalYAML() (interface{}, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+
+	for _, kv := range m {
+		key, value := &yaml.Node{}, &yaml.Node{}
+		key.SetString(kv.key)
+
+		if err := value.Encode(kv.value); err != nil {
+			return nil, err
+		}
+
+		node.Content = append(node.Content, key, value)
+	}
+
+	return node, nil
+}
+
+// marshalYAML formats a CI configuration as YAML indented by two spaces
+func marshalYAML(value interface{}) (string, error) {
+	var result bytes.Buffer
+
+	encoder := yaml.NewEncoder(&result)
+	encoder.SetIndent(2)
+
+	if err := encoder.Encode(value); err != nil {
+		return "", err
+	}
+
+	if err := encoder.Close(); err != nil {
+		return "", err
+	}
+
+	return result.String(), nil
+}
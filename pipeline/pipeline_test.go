@@ -0,0 +1,70 @@
+package pipeline
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/charypar/monobuild/graph"
+	"github.com/charypar/monobuild/manifests"
+)
+
+var exampleDependencies = graph.New(map[string][]graph.Edge{
+	"apps/app1": []graph.Edge{{Label: "libs/lib1", Colour: graph.Strong}},
+	"apps/app2": []graph.Edge{},
+	"libs/lib1": []graph.Edge{},
+	"stack1":    []graph.Edge{{Label: "apps/app1", Colour: graph.Strong}, {Label: "apps/app2", Colour: graph.Strong}, {Label: "libs/lib1", Colour: graph.Weak}},
+})
+
+var exampleSchedule = exampleDependencies.FilterEdges([]int{graph.Strong})
+
+var exampleComponents = map[string]manifests.Component{
+	"apps/app1": {Name: "apps/app1", Commands: manifests.Commands{Build: "go build ./..."}, Timeout: 90 * time.Second},
+}
+
+var examplePipeline = Pipeline{
+	Name: "ci",
+	Jobs: []Job{
+		{Component: "apps/app1", ID: "apps-app1", Needs: []string{"libs/lib1"}, Uses: []string{}, Stage: 1, Command: "go build ./...", Timeout: 90 * time.Second},
+		{Component: "libs/lib1", ID: "libs-lib1", Needs: []string{}, Uses: []string{}, Stage: 0, Command: "make build"},
+		{Component: "stack1", ID: "stack1", Needs: []string{"apps/app1"}, Uses: []string{"libs/lib1"}, Stage: 2, Command: "make build"},
+	},
+	Stages: 3,
+}
+
+func Test_jobIDs(t *testing.T) {
+	got := jobIDs([]string{"apps/app1", "apps-app1", "1st", "web.site", "a b"})
+	want := map[string]string{
+		"apps/app1": "apps-app1",
+		"apps-app1": "apps-app1_2",
+		"1st":       "_1st",
+		"web.site":  "web-site",
+		"a b":       "a-b",
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("jobIDs() = %v, want %v", got, want)
+	}
+}
+
+func TestNew(t *testing.T) {
+	opts := Options{Name: "ci", BuildCommand: "make build"}
+
+	got, err := New(exampleDependencies, exampleSchedule, []string{"apps/app1", "libs/lib1", "stack1"}, exampleComponents, opts)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(got, examplePipeline) {
+		t.Errorf("New() = %v, want %v", got, examplePipeline)
+	}
+
+	empty, err := New(exampleDependencies, exampleSchedule, []string{}, exampleComponents, opts)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(empty, Pipeline{Name: "ci", Jobs: []Job{}}) {
+		t.Errorf("New() = %v, want an empty pipeline", empty)
+	}
+}
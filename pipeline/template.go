@@ -0,0 +1,69 @@
+package pipeline
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// Edge is a dependency between jobs of a pipeline
+type Edge struct {
+	From   string // Component depending on the other
+	To     string
+	Strong bool
+}
+
+// TemplateData is the data available to pipeline templates
+type TemplateData struct {
+	Name         string
+	Jobs         []Job    // Jobs sorted by component
+	Stages       [][]Job  // Jobs grouped by stage, jobs only need jobs in earlier stages
+	Edges        []Edge   // Dependencies between jobs
+	Changed      []string // Components with changed files
+	ChangedFiles []string
+}
+
+var templateFunctions = template.FuncMap{
+	"join":  strings.Join,
+	"quote": strconv.Quote,
+	"add":   func(a int, b int) int { return a + b },
+}
+
+// Template renders the pipeline with a text/template, which can generate
+// configuration for any CI system. Besides the standard functions, templates
+// can use 'join' (strings.Join), 'quote' (strconv.Quote) and 'add'.
+func Template(p Pipeline, source string, changed []string, changedFiles []string) (string, error) {
+	tmpl, err := template.New("pipeline").Funcs(templateFunctions).Parse(source)
+	if err != nil {
+		return "", fmt.Errorf("cannot parse pipeline template: %s", err)
+	}
+
+	data := TemplateData{
+		Name:         p.Name,
+		Jobs:         p.Jobs,
+		Stages:       make([][]Job, p.Stages),
+		Edges:        []Edge{},
+		Changed:      changed,
+		ChangedFiles: changedFiles,
+	}
+
+	for _, j := range p.Jobs {
+		data.Stages[j.Stage] = append(data.Stages[j.Stage], j)
+
+		for _, n := range j.Needs {
+			data.Edges = append(data.Edges, Edge{j.Component, n, true})
+		}
+		for _, u := range j.Uses {
+			data.Edges = append(data.Edges, Edge{j.Component, u, false})
+		}
+	}
+
+	var result bytes.Buffer
+	if err := tmpl.Execute(&result, data); err != nil {
+		return "", fmt.Errorf("cannot render pipeline template: %s", err)
+	}
+
+	return result.String(), nil
+}
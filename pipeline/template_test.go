@@ -0,0 +1,90 @@
+package pipeline
+
+import (
+	"testing"
+)
+
+func TestTemplate(t *testing.T) {
+	tagged := examplePipeline
+	tagged.Jobs = []Job{examplePipeline.Jobs[1]}
+	tagged.Jobs[0].Tags = []string{"go", "library"}
+	tagged.Stages = 1
+
+	tests := []struct {
+		name         string
+		pipeline     Pipeline
+		source       string
+		changed      []string
+		changedFiles []string
+		want         string
+		wantErr      bool
+	}{
+		{
+			"renders stages",
+			examplePipeline,
+			`{{range $i, $stage := .Stages}}stage {{add $i 1}}:{{range $stage}} {{.Component}} ({{.Command}}){{end}}
+{{end}}`,
+			[]string{},
+			[]string{},
+			`stage 1: libs/lib1 (make build)
+stage 2: apps/app1 (go build ./...)
+stage 3: stack1 (make build)
+`,
+			false,
+		},
+		{
+			"renders edges",
+			examplePipeline,
+			`{{range .Edges}}{{.From}} -> {{.To}}{{if .Strong}} (strong){{end}}
+{{end}}`,
+			[]string{},
+			[]string{},
+			`apps/app1 -> libs/lib1 (strong)
+stack1 -> apps/app1 (strong)
+stack1 -> libs/lib1
+`,
+			false,
+		},
+		{
+			"renders tags and changes",
+			tagged,
+			`{{.Name}}: {{range .Jobs}}{{quote .Component}} [{{join .Tags ", "}}]{{end}}
+changed: {{join .Changed ", "}} in {{join .ChangedFiles ", "}}`,
+			[]string{"libs/lib1"},
+			[]string{"libs/lib1/main.go"},
+			`ci: "libs/lib1" [go, library]
+changed: libs/lib1 in libs/lib1/main.go`,
+			false,
+		},
+		{
+			"fails to parse a broken template",
+			examplePipeline,
+			`{{range .Jobs}}`,
+			[]string{},
+			[]string{},
+			"",
+			true,
+		},
+		{
+			"fails to render a missing field",
+			examplePipeline,
+			`{{.Missing}}`,
+			[]string{},
+			[]string{},
+			"",
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Template(tt.pipeline, tt.source, tt.changed, tt.changedFiles)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Template() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("Template() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package report
+
+import (
+	"bytes"
+	_ "embed" // for the viewer template
+	"fmt"
+	"html/template"
+
+	"github.com/charypar/monobuild/graph"
+	"github.com/charypar/monobuild/set"
+)
+
+//go:embed report.html
+var viewer string
+
+var reportTemplate = template.Must(template.New("report").Parse(viewer))
+
+// Dependency is a dependency of a component in the report
+type Dependency struct {
+	Name   string `json:"name"`
+	Strong bool   `json:"strong"`
+}
+
+// Component is a component shown in the report
+type Component struct {
+	Name         string       `json:"name"`
+	State        string       `json:"state,omitempty"` // changed, impacted or untouched when highlighted
+	Dependencies []Dependency `json:"dependencies"`
+	Schedule     []string     `json:"schedule"` // dependencies in the build schedule
+}
+
+// Data holds everything shown in the report
+type Data struct {
+	Title      string      `json:"title"`
+	Components []Component `json:"components"`
+}
+
+// components collects the selected components with their dependencies within
+// the selection. Components are coloured by their state when the highlight has
+// any components.
+func components(dependencies graph.Graph, schedule graph.Graph, selection []string, highlight graph.Highlight) []Component {
+	selected := set.New(selection)
+	changed := set.New(highlight.Changed)
+	impacted := set.New(highlight.Impacted)
+	highlighted := len(highlight.Changed) > 0 || len(highlight.Impacted) > 0
+	strong := dependencies.FilterEdges([]int{graph.Strong})
+
+	result := []Component{}
+	for _, c := range dependencies.Vertices() {
+		if !selected.Has(c) {
+			continue
+		}
+
+		component := Component{Name: c, Dependencies: []Dependency{}, Schedule: []string{}}
+
+		if highlighted {
+			switch {
+			case changed.Has(c):
+				component.State = "changed"
+			case impacted.Has(c):
+				component.State = "impacted"
+			default:
+				component.State = "untouched"
+			}
+		}
+
+		isStrong := set.New(strong.Children([]string{c}))
+		for _, d := range dependencies.Children([]string{c}) {
+			if selected.Has(d) {
+				component.Dependencies = append(component.Dependencies, Dependency{d, isStrong.Has(d)})
+			}
+		}
+
+		for _, d := range schedule.Children([]string{c}) {
+			if selected.Has(d) {
+				component.Schedule = append(component.Schedule, d)
+			}
+		}
+
+		result = append(result, component)
+	}
+
+	return result
+}
+
+// HTML returns a self-contained HTML page with an interactive viewer of the
+// selected part of the dependency graph and build schedule. The page makes
+// no network requests, the data and the viewer are embedded in it.
+func HTML(dependencies graph.Graph, schedule graph.Graph, selection []string, highlight graph.Highlight) (string, error) {
+	data := Data{
+		Title:      "monobuild report",
+		Components: components(dependencies, schedule, selection, highlight),
+	}
+
+	var result bytes.Buffer
+	if err := reportTemplate.Execute(&result, data); err != nil {
+		return "", fmt.Errorf("cannot render report: %s", err)
+	}
+
+	return result.String(), nil
+}
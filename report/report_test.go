@@ -0,0 +1,79 @@
+package report
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/charypar/monobuild/graph"
+)
+
+var exampleDependencies = graph.New(map[string][]graph.Edge{
+	"app1":   []graph.Edge{{Label: "lib1", Colour: graph.Weak}, {Label: "lib2", Colour: graph.Weak}},
+	"lib1":   []graph.Edge{},
+	"lib2":   []graph.Edge{},
+	"stack1": []graph.Edge{{Label: "app1", Colour: graph.Strong}},
+})
+
+var exampleSchedule = exampleDependencies.FilterEdges([]int{graph.Strong})
+
+func Test_components(t *testing.T) {
+	tests := []struct {
+		name      string
+		selection []string
+		highlight graph.Highlight
+		want      []Component
+	}{
+		{
+			"returns nothing for an empty selection",
+			[]string{},
+			graph.Highlight{},
+			[]Component{},
+		},
+		{
+			"returns selected components with dependencies in the selection",
+			[]string{"app1", "lib1", "stack1"},
+			graph.Highlight{},
+			[]Component{
+				{Name: "app1", Dependencies: []Dependency{{"lib1", false}}, Schedule: []string{}},
+				{Name: "lib1", Dependencies: []Dependency{}, Schedule: []string{}},
+				{Name: "stack1", Dependencies: []Dependency{{"app1", true}}, Schedule: []string{"app1"}},
+			},
+		},
+		{
+			"sets the state of highlighted components",
+			[]string{"app1", "lib1", "lib2"},
+			graph.Highlight{Changed: []string{"lib1"}, Impacted: []string{"app1", "lib1"}},
+			[]Component{
+				{Name: "app1", State: "impacted", Dependencies: []Dependency{{"lib1", false}, {"lib2", false}}, Schedule: []string{}},
+				{Name: "lib1", State: "changed", Dependencies: []Dependency{}, Schedule: []string{}},
+				{Name: "lib2", State: "untouched", Dependencies: []Dependency{}, Schedule: []string{}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := components(exampleDependencies, exampleSchedule, tt.selection, tt.highlight); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("components() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTML(t *testing.T) {
+	got, err := HTML(exampleDependencies, exampleSchedule, []string{"app1", "stack1"}, graph.Highlight{})
+	if err != nil {
+		t.Fatalf("HTML() error = %v", err)
+	}
+
+	data := `const data = {"title":"monobuild report","components":[{"name":"app1","dependencies":[],"schedule":[]},{"name":"stack1","dependencies":[{"name":"app1","strong":true}],"schedule":["app1"]}]};`
+	if !strings.Contains(got, data) {
+		t.Errorf("HTML() does not embed the data %s", data)
+	}
+
+	for _, external := range []string{"src=", "href=", "@import", "fetch("} {
+		if strings.Contains(got, external) {
+			t.Errorf("HTML() refers to external resources with '%s'", external)
+		}
+	}
+}
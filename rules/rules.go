@@ -0,0 +1,241 @@
+package rules
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+
+	"github.com/bmatcuk/doublestar"
+	"github.com/charypar/monobuild/config"
+	"github.com/charypar/monobuild/graph"
+	"github.com/charypar/monobuild/set"
+)
+
+// Kind of rule
+type Kind int
+
+// Deny forbids dependencies of matched components on matched targets
+var Deny Kind = 1
+
+// Only allows matched components to depend on matched targets only
+var Only Kind = 2
+
+// matcher matches components by a glob pattern or a tag expression
+type matcher struct {
+	pattern string
+	tag     bool // pattern is a tag expression
+	strong  bool // only matches strong dependencies
+}
+
+func (m matcher) matches(component string, tags map[string]set.Set) bool {
+	if m.tag {
+		return config.MatchesTags(m.pattern, tags[component])
+	}
+
+	matched, _ := doublestar.Match(m.pattern, component)
+	return matched
+}
+
+func matchesAny(matchers []matcher, component string, tags map[string]set.Set) bool {
+	for _, m := range matchers {
+		if m.matches(component, tags) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Rule is a constraint on dependencies between components
+type Rule struct {
+	Kind     Kind
+	Location string // location of the rule in the rules file
+	Text     string // the rule as written
+
+	from []matcher
+	to   []matcher
+}
+
+func (r Rule) String() string {
+	return r.Text
+}
+
+// Violation is a dependency breaking a rule
+type Violation struct {
+	Rule       Rule
+	Component  string
+	Dependency string
+	Strong     bool
+}
+
+// Read reads rules from a file. Each line holds a rule in the format
+//
+// <deny|only> <components> -> <dependencies>
+//
+// where both sides are comma separated lists of glob patterns (libs/*) or tag
+// expressions prefixed with 'tag:' (tag:team:payments+service). Dependencies
+// prefixed with '!' only match strong dependencies.
+//
+// A 'deny' rule forbids the components to depend on any of the dependencies.
+// An 'only' rule forbids the components to depend on anything else than the
+// dependencies. For example
+//
+// deny libs/** -> apps/**
+// deny ** -> !stack*
+// only tag:team:a -> libs/**, tag:team:a
+func Read(path string) ([]Rule, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open rules %s: %s", path, err)
+	}
+	defer file.Close()
+
+	rules := []Rule{}
+
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if len(text) < 1 || text[0] == '#' { // skip blank lines and comments
+			continue
+		}
+
+		rule, err := Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %s", path, line, err)
+		}
+
+		rule.Location = fmt.Sprintf("%s:%d", path, line)
+		rules = append(rules, rule)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("cannot read rules %s: %s", path, err)
+	}
+
+	return rules, nil
+}
+
+// Parse parses a single rule, see Read for the format
+func Parse(text string) (Rule, error) {
+	text = strings.TrimSpace(text)
+	rule := Rule{Text: text}
+
+	// the keyword is separated from the rest by any whitespace
+	fields := []string{text}
+	if i := strings.IndexFunc(text, unicode.IsSpace); i >= 0 {
+		fields = []string{text[:i], strings.TrimLeftFunc(text[i:], unicode.IsSpace)}
+	}
+
+	switch fields[0] {
+	case "deny":
+		rule.Kind = Deny
+	case "only":
+		rule.Kind = Only
+	default:
+		return Rule{}, fmt.Errorf("unknown rule '%s', expected 'deny' or 'only'", fields[0])
+	}
+
+	sides := []string{}
+	if len(fields) > 1 {
+		sides = strings.Split(fields[1], "->")
+	}
+	if len(sides) != 2 {
+		return Rule{}, fmt.Errorf("bad rule format: '%s' expected '%s <components> -> <dependencies>'", text, fields[0])
+	}
+
+	var err error
+
+	rule.from, err = parseMatchers(sides[0], false)
+	if err != nil {
+		return Rule{}, err
+	}
+
+	rule.to, err = parseMatchers(sides[1], true)
+	if err != nil {
+		return Rule{}, err
+	}
+
+	return rule, nil
+}
+
+func parseMatchers(list string, dependencies bool) ([]matcher, error) {
+	matchers := []matcher{}
+
+	for _, item := range strings.Split(list, ",") {
+		m := matcher{pattern: strings.TrimSpace(item)}
+
+		if strings.HasPrefix(m.pattern, "!") {
+			if !dependencies {
+				return nil, fmt.Errorf("'%s': only dependencies can be strong", m.pattern)
+			}
+
+			m.strong = true
+			m.pattern = m.pattern[1:]
+		}
+
+		if strings.HasPrefix(m.pattern, "tag:") {
+			m.tag = true
+			m.pattern = strings.TrimPrefix(m.pattern, "tag:")
+		} else {
+			m.pattern = strings.TrimRight(m.pattern, "/")
+
+			if _, err := doublestar.Match(m.pattern, ""); err != nil {
+				return nil, fmt.Errorf("bad component pattern '%s': %s", m.pattern, err)
+			}
+		}
+
+		if m.pattern == "" {
+			return nil, fmt.Errorf("missing component pattern in '%s'", strings.TrimSpace(list))
+		}
+
+		matchers = append(matchers, m)
+	}
+
+	return matchers, nil
+}
+
+// violates checks whether a dependency breaks the rule
+func (r Rule) violates(component string, dependency string, strong bool, tags map[string]set.Set) bool {
+	if !matchesAny(r.from, component, tags) {
+		return false
+	}
+
+	matched := false
+	for _, m := range r.to {
+		if (strong || !m.strong) && m.matches(dependency, tags) {
+			matched = true
+			break
+		}
+	}
+
+	if r.Kind == Only {
+		return !matched
+	}
+
+	return matched
+}
+
+// Check finds dependencies in the graph breaking any of the rules. Tags of
+// components are used to match tag expressions.
+func Check(rules []Rule, dependencies graph.Graph, tags map[string]set.Set) []Violation {
+	violations := []Violation{}
+	strong := dependencies.FilterEdges([]int{graph.Strong})
+
+	for _, component := range dependencies.Vertices() {
+		strongDeps := set.New(strong.Children([]string{component}))
+
+		for _, dependency := range dependencies.Children([]string{component}) {
+			isStrong := strongDeps.Has(dependency)
+
+			for _, rule := range rules {
+				if rule.violates(component, dependency, isStrong, tags) {
+					violations = append(violations, Violation{rule, component, dependency, isStrong})
+				}
+			}
+		}
+	}
+
+	return violations
+}
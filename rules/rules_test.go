@@ -0,0 +1,170 @@
+package rules
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/charypar/monobuild/graph"
+	"github.com/charypar/monobuild/set"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		want    Rule
+		wantErr bool
+	}{
+		{
+			"parses a deny rule",
+			"deny libs/** -> apps/*",
+			Rule{Kind: Deny, Text: "deny libs/** -> apps/*", from: []matcher{{"libs/**", false, false}}, to: []matcher{{"apps/*", false, false}}},
+			false,
+		},
+		{
+			"parses an only rule with tags and lists",
+			"only tag:team:a -> libs/**, tag:team:a",
+			Rule{Kind: Only, Text: "only tag:team:a -> libs/**, tag:team:a", from: []matcher{{"team:a", true, false}}, to: []matcher{{"libs/**", false, false}, {"team:a", true, false}}},
+			false,
+		},
+		{
+			"parses strong dependencies",
+			"deny ** -> !stack*",
+			Rule{Kind: Deny, Text: "deny ** -> !stack*", from: []matcher{{"**", false, false}}, to: []matcher{{"stack*", false, true}}},
+			false,
+		},
+		{
+			"parses a rule separated with tabs",
+			"deny\tlibs/**\t->\tapps/*",
+			Rule{Kind: Deny, Text: "deny\tlibs/**\t->\tapps/*", from: []matcher{{"libs/**", false, false}}, to: []matcher{{"apps/*", false, false}}},
+			false,
+		},
+		{"fails on an unknown rule", "allow a -> b", Rule{}, true},
+		{"fails on a missing arrow", "deny a b", Rule{}, true},
+		{"fails on a missing side", "deny a ->", Rule{}, true},
+		{"fails on strong components", "deny !a -> b", Rule{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.text)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules")
+	content := "# layering\ndeny libs/** -> apps/*\n\nonly apps/* -> libs/**\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	rules, err := Read(path)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+
+	got := []string{}
+	for _, r := range rules {
+		got = append(got, r.Location+" "+r.String())
+	}
+
+	want := []string{path + ":2 deny libs/** -> apps/*", path + ":4 only apps/* -> libs/**"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Read() = %v, want %v", got, want)
+	}
+
+	if err := os.WriteFile(path, []byte("deny libs/**\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Read(path); err == nil {
+		t.Errorf("Read() expected an error for a bad rule")
+	}
+
+	if _, err := Read(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Errorf("Read() expected an error for a missing file")
+	}
+}
+
+func TestCheck(t *testing.T) {
+	dependencies := graph.New(map[string][]graph.Edge{
+		"apps/app1": {{Label: "libs/lib1", Colour: graph.Weak}, {Label: "apps/app2", Colour: graph.Weak}},
+		"apps/app2": {{Label: "libs/lib1", Colour: graph.Weak}},
+		"libs/lib1": {{Label: "libs/lib2", Colour: graph.Weak}},
+		"libs/lib2": {{Label: "apps/app2", Colour: graph.Weak}},
+		"stack1":    {{Label: "apps/app1", Colour: graph.Strong}, {Label: "stack2", Colour: graph.Weak}},
+		"stack2":    {},
+	})
+	tags := map[string]set.Set{
+		"apps/app1": set.New([]string{"team:a"}),
+		"libs/lib1": set.New([]string{"team:a"}),
+	}
+
+	type violation struct {
+		component  string
+		dependency string
+	}
+
+	tests := []struct {
+		name  string
+		rules []string
+		want  []violation
+	}{
+		{
+			"finds dependencies denied by glob",
+			[]string{"deny libs/** -> apps/*"},
+			[]violation{{"libs/lib2", "apps/app2"}},
+		},
+		{
+			"only denies strong dependencies on strong targets",
+			[]string{"deny ** -> !apps/app1, !stack2"},
+			[]violation{{"stack1", "apps/app1"}},
+		},
+		{
+			"finds dependencies not allowed by tag",
+			[]string{"only tag:team:a -> libs/lib1"},
+			[]violation{{"apps/app1", "apps/app2"}, {"libs/lib1", "libs/lib2"}},
+		},
+		{
+			"allows strong dependencies only on strong targets",
+			[]string{"only stack1 -> !apps/*"},
+			[]violation{{"stack1", "stack2"}},
+		},
+		{
+			"finds nothing when rules are kept",
+			[]string{"deny apps/* -> stack*"},
+			[]violation{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := []Rule{}
+			for _, text := range tt.rules {
+				rule, err := Parse(text)
+				if err != nil {
+					t.Fatalf("Parse() error = %v", err)
+				}
+
+				rules = append(rules, rule)
+			}
+
+			got := []violation{}
+			for _, v := range Check(rules, dependencies, tags) {
+				got = append(got, violation{v.Component, v.Dependency})
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Check() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
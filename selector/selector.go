@@ -0,0 +1,290 @@
+package selector
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bmatcuk/doublestar"
+	"github.com/charypar/monobuild/graph"
+	"github.com/charypar/monobuild/set"
+)
+
+// Selector is a parsed selector expression
+type Selector struct {
+	expression string
+	root       node
+}
+
+// Parse parses a selector expression.
+//
+// An expression is built from patterns matching component names, which can be
+// globs (libs/*). A pattern prefixed with '...' also selects everything that
+// depends on the matched components, a pattern suffixed with '...' also
+// selects everything the matched components depend on.
+//
+// Selections are combined with '|' or ',' (union), '&' (intersection) and
+// ' - ' (difference, the minus needs to be surrounded by spaces). Intersection
+// binds tighter than union and difference, parentheses can be used for
+// grouping, e.g. "(libs/* | ...app1) - libs/lib3"
+func Parse(expression string) (Selector, error) {
+	p := parser{tokens: tokenize(expression)}
+
+	if len(p.tokens) < 1 {
+		return Selector{}, fmt.Errorf("empty selector")
+	}
+
+	root, err := p.expression()
+	if err != nil {
+		return Selector{}, err
+	}
+
+	if !p.done() {
+		return Selector{}, fmt.Errorf("unexpected '%s' in selector '%s'", p.peek(), expression)
+	}
+
+	return Selector{expression, root}, nil
+}
+
+// Select returns the vertices of the dependency graph selected by the selector
+func (s Selector) Select(dependencies graph.Graph) (set.Set, error) {
+	ctx := context{dependencies, dependencies.Reverse(), dependencies.Vertices()}
+
+	return s.root.eval(ctx)
+}
+
+func (s Selector) String() string {
+	return s.expression
+}
+
+// Evaluation
+
+type context struct {
+	dependencies graph.Graph
+	dependents   graph.Graph
+	vertices     []string
+}
+
+type node interface {
+	eval(ctx context) (set.Set, error)
+}
+
+// pattern selects components by name or glob, with optional traversal
+type pattern struct {
+	glob         string
+	dependents   bool // prefixed with '...'
+	dependencies bool // suffixed with '...'
+}
+
+func (p pattern) match(vertices []string) ([]string, error) {
+	matched := []string{}
+
+	for _, v := range vertices {
+		ok, err := doublestar.Match(p.glob, v)
+		if err != nil {
+			return nil, fmt.Errorf("bad pattern '%s': %s", p.glob, err)
+		}
+
+		if ok {
+			matched = append(matched, v)
+		}
+	}
+
+	if len(matched) < 1 {
+		return nil, fmt.Errorf("'%s' does not match any component", p.glob)
+	}
+
+	return matched, nil
+}
+
+func (p pattern) eval(ctx context) (set.Set, error) {
+	matched, err := p.match(ctx.vertices)
+	if err != nil {
+		return set.Set{}, err
+	}
+
+	result := set.New(matched)
+
+	if p.dependents {
+		result = result.Union(set.New(ctx.dependents.Descendants(matched)))
+	}
+
+	if p.dependencies {
+		result = result.Union(set.New(ctx.dependencies.Descendants(matched)))
+	}
+
+	return result, nil
+}
+
+type operation struct {
+	operator string
+	left     node
+	right    node
+}
+
+func (o operation) eval(ctx context) (set.Set, error) {
+	left, err := o.left.eval(ctx)
+	if err != nil {
+		return set.Set{}, err
+	}
+
+	right, err := o.right.eval(ctx)
+	if err != nil {
+		return set.Set{}, err
+	}
+
+	switch o.operator {
+	case "&":
+		return left.Intersect(right), nil
+	case "-":
+		return left.Without(right), nil
+	default:
+		return left.Union(right), nil
+	}
+}
+
+// Parsing
+
+func isOperator(token string) bool {
+	switch token {
+	case "(", ")", "|", ",", "&", "-":
+		return true
+	}
+
+	return false
+}
+
+func tokenize(expression string) []string {
+	tokens := []string{}
+	current := strings.Builder{}
+
+	flush := func() {
+		if current.Len() > 0 {
+			tokens = append(tokens, current.String())
+			current.Reset()
+		}
+	}
+
+	for _, r := range expression {
+		switch r {
+		case ' ', '\t', '\n':
+			flush()
+		case '(', ')', '|', ',', '&':
+			flush()
+			tokens = append(tokens, string(r))
+		default:
+			current.WriteRune(r)
+		}
+	}
+	flush()
+
+	return tokens
+}
+
+type parser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *parser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *parser) peek() string {
+	if p.done() {
+		return ""
+	}
+
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() string {
+	token := p.peek()
+	p.pos++
+
+	return token
+}
+
+// expression := term (('|' | ',' | '-') term)*
+func (p *parser) expression() (node, error) {
+	left, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek() == "|" || p.peek() == "," || p.peek() == "-" {
+		operator := p.next()
+
+		right, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+
+		left = operation{operator, left, right}
+	}
+
+	return left, nil
+}
+
+// term := factor ('&' factor)*
+func (p *parser) term() (node, error) {
+	left, err := p.factor()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek() == "&" {
+		operator := p.next()
+
+		right, err := p.factor()
+		if err != nil {
+			return nil, err
+		}
+
+		left = operation{operator, left, right}
+	}
+
+	return left, nil
+}
+
+// factor := '(' expression ')' | pattern
+func (p *parser) factor() (node, error) {
+	if p.done() {
+		return nil, fmt.Errorf("unexpected end of selector")
+	}
+
+	token := p.next()
+
+	if token == "(" {
+		inner, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+
+		if p.next() != ")" {
+			return nil, fmt.Errorf("missing ')' in selector")
+		}
+
+		return inner, nil
+	}
+
+	if isOperator(token) {
+		return nil, fmt.Errorf("unexpected '%s' in selector", token)
+	}
+
+	pat := pattern{}
+	if strings.HasPrefix(token, "...") {
+		pat.dependents = true
+		token = strings.TrimPrefix(token, "...")
+	}
+	if strings.HasSuffix(token, "...") {
+		pat.dependencies = true
+		token = strings.TrimSuffix(token, "...")
+	}
+
+	pat.glob = strings.TrimRight(token, "/")
+	if pat.glob == "" {
+		return nil, fmt.Errorf("missing component pattern in selector")
+	}
+
+	return pat, nil
+}
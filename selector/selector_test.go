@@ -0,0 +1,71 @@
+package selector
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/charypar/monobuild/graph"
+)
+
+var exampleDependencies = graph.New(map[string][]graph.Edge{
+	"app1":      []graph.Edge{{Label: "libs/lib1", Colour: graph.Weak}, {Label: "libs/lib2", Colour: graph.Weak}},
+	"app2":      []graph.Edge{{Label: "libs/lib2", Colour: graph.Weak}},
+	"libs/lib1": []graph.Edge{{Label: "libs/lib3", Colour: graph.Weak}},
+	"libs/lib2": []graph.Edge{{Label: "libs/lib3", Colour: graph.Weak}},
+	"libs/lib3": []graph.Edge{},
+	"stack1":    []graph.Edge{{Label: "app1", Colour: graph.Strong}, {Label: "app2", Colour: graph.Strong}},
+})
+
+func TestSelector_Select(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		want       []string
+		wantErr    bool
+	}{
+		{"selects a single component", "app1", []string{"app1"}, false},
+		{"ignores a trailing slash", "libs/lib1/", []string{"libs/lib1"}, false},
+		{"selects by glob", "libs/*", []string{"libs/lib1", "libs/lib2", "libs/lib3"}, false},
+		{"selects dependencies", "app1...", []string{"app1", "libs/lib1", "libs/lib2", "libs/lib3"}, false},
+		{"selects dependents", "...libs/lib1", []string{"app1", "libs/lib1", "stack1"}, false},
+		{"selects dependents and dependencies", "...libs/lib1...", []string{"app1", "libs/lib1", "libs/lib3", "stack1"}, false},
+		{"unions with a pipe", "app1 | app2", []string{"app1", "app2"}, false},
+		{"unions with a comma", "app1,app2", []string{"app1", "app2"}, false},
+		{"intersects", "app1... & app2...", []string{"libs/lib2", "libs/lib3"}, false},
+		{"subtracts", "libs/* - libs/lib3", []string{"libs/lib1", "libs/lib2"}, false},
+		{"binds intersection tighter", "app1 | app1... & app2...", []string{"app1", "libs/lib2", "libs/lib3"}, false},
+		{"groups with parentheses", "(libs/* | ...app1) - libs/lib3", []string{"app1", "libs/lib1", "libs/lib2", "stack1"}, false},
+		{"fails on unknown component", "app3", nil, true},
+		{"fails on a dangling operator", "app1 &", nil, true},
+		{"fails on unbalanced parentheses", "(app1 | app2", nil, true},
+		{"fails on an empty selector", " ", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Parse(tt.expression)
+			if err != nil {
+				if !tt.wantErr {
+					t.Errorf("Parse() error = %v", err)
+				}
+				return
+			}
+
+			selected, err := s.Select(exampleDependencies)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Selector.Select() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+
+			got := selected.AsStrings()
+			sort.Strings(got)
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Selector.Select() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
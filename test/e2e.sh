@@ -94,6 +94,80 @@ libs/lib3: "
 
 assert_eq "monobuild print --dependencies --scope app1" "$actual" "$expected"
 
+# monobuild print --dependencies --scope 'app2 | app3'
+actual=$($mb print --dependencies --scope 'app2 | app3')
+expected="app2: libs/lib2, libs/lib3
+app3: app4/lib, libs/lib3
+app4/lib: 
+libs/lib2: libs/lib3
+libs/lib3: "
+
+assert_eq "monobuild print --dependencies --scope 'app2 | app3'" "$actual" "$expected"
+
+# monobuild print --dependencies --scope '...libs/lib1 & app*'
+actual=$($mb print --dependencies --scope '...libs/lib1 & app*')
+expected="app1: libs/lib1, libs/lib2
+libs/lib1: libs/lib3
+libs/lib2: libs/lib3
+libs/lib3: "
+
+assert_eq "monobuild print --dependencies --scope '...libs/lib1 & app*'" "$actual" "$expected"
+
+# monobuild print --dependencies --scope stack1 --depth 2
+actual=$($mb print --dependencies --scope stack1 --depth 2)
+expected="app1: libs/lib1, libs/lib2
+app2: libs/lib2, libs/lib3
+app3: app4/lib, libs/lib3
+app4/lib: 
+libs/lib1: libs/lib3
+libs/lib2: libs/lib3
+libs/lib3: 
+stack1: app1, app2, app3"
+
+assert_eq "monobuild print --dependencies --scope stack1 --depth 2" "$actual" "$expected"
+
+# monobuild diff --scope stack1 --depth 1
+changes="libs/lib2/change.txt
+app4/app.bin"
+
+actual=$(echo "$changes" | $mb diff --dependencies --scope stack1 --depth 1 -)
+expected="app1: 
+app2: 
+stack1: app1, app2"
+
+assert_eq "monobuild diff --dependencies --scope stack1 --depth 1" "$actual" "$expected"
+
+# monobuild print --dependencies --dependents-of libs/lib3 --depth 1
+actual=$($mb print --dependencies --dependents-of libs/lib3 --depth 1)
+expected="app2: libs/lib2, libs/lib3
+app3: libs/lib3
+libs/lib1: libs/lib3
+libs/lib2: libs/lib3
+libs/lib3: "
+
+assert_eq "monobuild print --dependencies --dependents-of libs/lib3 --depth 1" "$actual" "$expected"
+
+# monobuild print --full --dependents-of app1 --strength strong
+actual=$($mb print --full --dependents-of app1 --strength strong)
+expected="app1: 
+stack1: !app1"
+
+assert_eq "monobuild print --full --dependents-of app1 --strength strong" "$actual" "$expected"
+
+# monobuild print --dependencies --reduce
+actual=$($mb print --dependencies --reduce)
+expected="app1: libs/lib1, libs/lib2
+app2: libs/lib2
+app3: app4/lib, libs/lib3
+app4: 
+app4/lib: 
+libs/lib1: libs/lib3
+libs/lib2: libs/lib3
+libs/lib3: 
+stack1: app1, app2, app3"
+
+assert_eq "monobuild print --dependencies --reduce" "$actual" "$expected"
+
 # monobuild print --full
 actual=$($mb print --full)
 expected="app1: libs/lib1, libs/lib2
@@ -267,6 +341,457 @@ stack1: !app1, !app2"
 
 assert_eq "monobuild diff --full" "$actual" "$expected"
 
+# monobuild diff --dot-highlight
+actual=$(echo "libs/lib1/change.txt" | $mb diff --dot-highlight --clusters --dependencies --scope 'app1 | libs/lib3' -)
+expected='digraph dependencies {
+  node [style=filled]
+  "app1" [fillcolor="#fddbc7"]
+  "app2" [fillcolor="white", color="grey", fontcolor="grey"]
+  "app3" [fillcolor="white", color="grey", fontcolor="grey"]
+  "app4" [fillcolor="white", color="grey", fontcolor="grey"]
+  "stack1" [fillcolor="white", color="grey", fontcolor="grey"]
+  subgraph "cluster_app4" {
+    label="app4/"
+    "app4/lib" [fillcolor="white", color="grey", fontcolor="grey"]
+  }
+  subgraph "cluster_libs" {
+    label="libs/"
+    "libs/lib1" [fillcolor="#f4a582"]
+    "libs/lib2" [fillcolor="white", color="grey", fontcolor="grey"]
+    "libs/lib3" [fillcolor="white", color="grey", fontcolor="grey"]
+  }
+  "app1" -> "libs/lib1" [style=dashed, label="weak"]
+  "app1" -> "libs/lib2" [style=dashed, label="weak"]
+  "app2" -> "libs/lib2" [style=dashed, label="weak"]
+  "app2" -> "libs/lib3" [style=dashed, label="weak"]
+  "app3" -> "app4/lib" [style=dashed, label="weak"]
+  "app3" -> "libs/lib3" [style=dashed, label="weak"]
+  "libs/lib1" -> "libs/lib3" [style=dashed, label="weak"]
+  "libs/lib2" -> "libs/lib3" [style=dashed, label="weak"]
+  "stack1" -> "app1" [label="strong"]
+  "stack1" -> "app2" [label="strong"]
+  "stack1" -> "app3" [label="strong"]
+}'
+
+assert_eq "monobuild diff --dot-highlight --clusters" "$actual" "$expected"
+
+# monobuild diff --format mermaid --highlight
+actual=$(echo "libs/lib1/change.txt" | $mb diff --format mermaid --highlight -)
+expected='graph LR
+  classDef changed fill:#f4a582
+  classDef impacted fill:#fddbc7
+  classDef untouched fill:#fff,stroke:#999,color:#999
+  n0["app1"]:::impacted
+  n1["app2"]:::untouched
+  n2["app3"]:::untouched
+  n3["app4"]:::untouched
+  n4["app4/lib"]:::untouched
+  n5["libs/lib1"]:::changed
+  n6["libs/lib2"]:::untouched
+  n7["libs/lib3"]:::untouched
+  n8["stack1"]:::impacted
+  n0 --> n8
+  n1 --> n8
+  n2 --> n8'
+
+assert_eq "monobuild diff --format mermaid --highlight" "$actual" "$expected"
+
+# monobuild print --format plantuml
+actual=$($mb print --dependencies --format plantuml --scope app2)
+expected='@startuml
+left to right direction
+  rectangle "app2" as n1
+  rectangle "libs/lib2" as n6
+  rectangle "libs/lib3" as n7
+  n1 ..> n6
+  n1 ..> n7
+  n6 ..> n7
+@enduml'
+
+assert_eq "monobuild print --format plantuml" "$actual" "$expected"
+
+# monobuild print --format html
+actual=$($mb print --dependencies --format html --scope app1 | grep "const data")
+expected='const data = {"title":"monobuild report","components":[{"name":"app1","dependencies":[{"name":"libs/lib1","strong":false},{"name":"libs/lib2","strong":false}],"schedule":[]},{"name":"libs/lib1","dependencies":[{"name":"libs/lib3","strong":false}],"schedule":[]},{"name":"libs/lib2","dependencies":[{"name":"libs/lib3","strong":false}],"schedule":[]},{"name":"libs/lib3","dependencies":[],"schedule":[]}]};'
+
+assert_eq "monobuild print --format html" "$actual" "$expected"
+
+# monobuild pipeline
+printf "\nPipeline command:\n"
+
+actual=$(echo "libs/lib2/change.txt" | $mb pipeline --target github-matrix -)
+expected='{"include":[{"component":"app1","id":"app1","build":"make build"},{"component":"app2","id":"app2","build":"make build"},{"component":"libs/lib2","id":"libs-lib2","build":"make build"},{"component":"stack1","id":"stack1","build":"make build"}]}'
+
+assert_eq "monobuild pipeline --target github-matrix" "$actual" "$expected"
+
+actual=$(echo "libs/lib2/change.txt" | $mb pipeline --target github-workflow --scope stack1 --build-command "make ci" - | grep -A3 "needs:")
+expected='    needs:
+      - app1
+      - app2
+    runs-on: ubuntu-latest'
+
+assert_eq "monobuild pipeline --target github-workflow" "$actual" "$expected"
+
+actual=$(echo "libs/lib2/change.txt" | $mb pipeline --target buildkite --scope app1 -)
+expected='steps:
+  - label: app1
+    key: app1
+    commands:
+      - cd app1
+      - make build
+    env:
+      BUILD_COMMAND: make build
+      COMPONENT: app1
+    depends_on:
+      - step: libs-lib2
+        allow_failure: true
+  - label: libs/lib2
+    key: libs-lib2
+    commands:
+      - cd libs/lib2
+      - make build
+    env:
+      BUILD_COMMAND: make build
+      COMPONENT: libs/lib2'
+
+assert_eq "monobuild pipeline --target buildkite" "$actual" "$expected"
+
+echo '{{range $i, $stage := .Stages}}{{add $i 1}}:{{range $stage}} {{.Component}}{{end}}
+{{end}}changed: {{join .Changed ", "}}' > pipeline.tmpl
+
+actual=$(echo "libs/lib2/change.txt" | $mb pipeline --target template --template pipeline.tmpl -)
+expected='1: app1 app2 libs/lib2
+2: stack1
+changed: libs/lib2'
+
+assert_eq "monobuild pipeline --target template" "$actual" "$expected"
+
+rm pipeline.tmpl
+
+actual=$(echo "libs/lib2/change.txt" | $mb pipeline --target ninja --scope app1 - | grep -A1 'stamp: build')
+expected='build $builddir/app1.stamp: build | app1/Dependencies
+  component = app1
+--
+build $builddir/libs-lib2.stamp: build | libs/lib2/Dependencies
+  component = libs/lib2'
+
+assert_eq "monobuild pipeline --target ninja" "$actual" "$expected"
+
+# monobuild record and --cached
+printf "\nBuild cache:\n"
+
+cache_dir=$(mktemp -d)
+
+actual=$(echo "libs/lib2/change.txt" | $mb diff --cached --cache "$cache_dir" -)
+expected="app1: 
+app2: 
+libs/lib2: 
+stack1: app1, app2"
+
+assert_eq "monobuild diff --cached with an empty cache" "$actual" "$expected"
+
+$mb record --cache "$cache_dir" libs/lib2 app2
+
+actual=$(echo "libs/lib2/change.txt" | $mb diff --cached --cache "$cache_dir" -)
+expected="app1: 
+stack1: app1"
+
+assert_eq "monobuild diff --cached after record" "$actual" "$expected"
+
+actual=$($mb print --cached --cache "$cache_dir" --scope stack1)
+expected="app1: 
+app3: 
+app4/lib: 
+libs/lib1: 
+libs/lib3: 
+stack1: app1, app3"
+
+assert_eq "monobuild print --cached" "$actual" "$expected"
+
+$mb cache-server --listen localhost:48213 --cache-dir "$cache_dir/server" 2> /dev/null &
+server_pid=$!
+sleep 1
+
+$mb record --cache http://localhost:48213 app1
+
+actual=$(echo "libs/lib2/change.txt" | $mb diff --cached --cache http://localhost:48213 -)
+expected="app2: 
+libs/lib2: 
+stack1: app2"
+
+assert_eq "monobuild diff --cached with a cache server" "$actual" "$expected"
+
+kill $server_pid
+rm -r "$cache_dir"
+
+# monobuild diff --merge-queue
+printf "\nMerge queue:\n"
+
+fixtures=$(pwd)
+repo=$(mktemp -d)
+cp -R . "$repo"
+cd "$repo"
+
+git="git -c user.name=monobuild -c user.email=monobuild@example.com"
+git init -q .
+git symbolic-ref HEAD refs/heads/main
+echo "shared" > app3/shared.txt
+git add . && $git commit -q -m "initial"
+
+git checkout -q -b feature
+echo "feature" > libs/lib1/feature.txt
+git add . && $git commit -q -m "feature"
+
+git checkout -q main
+echo "main" > app2/main.txt
+git add . && $git commit -q -m "main"
+
+# a merge commit which also changes a file itself
+$git merge -q --no-commit feature > /dev/null 2>&1
+echo "merged" > app3/shared.txt
+git add . && $git commit -q -m "merge"
+
+actual=$(git rev-parse HEAD^@ | wc -l | tr -d ' ')
+assert_eq "merge commit has both parents" "$actual" "2"
+
+actual=$($mb diff --merge-queue)
+expected="app1: 
+app2: 
+app3: 
+libs/lib1: 
+stack1: app1, app2, app3"
+
+assert_eq "monobuild diff --merge-queue" "$actual" "$expected"
+
+actual=$($mb diff --merge-queue --combine intersection)
+expected="app3: 
+stack1: app3"
+
+assert_eq "monobuild diff --merge-queue --combine intersection" "$actual" "$expected"
+
+actual=$($mb diff --merge-queue --base-commit HEAD^1 2>&1 | grep -c "cannot be used with --merge-queue")
+assert_eq "monobuild diff --merge-queue rejects base commits" "$actual" "1"
+
+actual=$($mb diff --merge-queue --main-branch 2>&1 | grep -c "Conflicting modes")
+assert_eq "monobuild diff rejects conflicting modes" "$actual" "1"
+
+$mb mark --markers refs/monobuild/success app1
+
+actual=$($mb diff --since-last-success --markers refs/monobuild/success --scope app1)
+expected="libs/lib1: 
+libs/lib2: 
+libs/lib3: "
+
+assert_eq "monobuild diff --since-last-success with ref markers" "$actual" "$expected"
+
+rm -rf .git
+
+actual=$($mb diff --since-last-success --markers refs/monobuild/success 2>&1 | grep -c "not a git repository")
+assert_eq "monobuild diff --since-last-success fails outside a repository" "$actual" "1"
+
+cd "$fixtures"
+rm -rf "$repo"
+
+# monobuild mark and --since-last-success
+printf "\nLast successful builds:\n"
+
+markers="$(mktemp -d)/markers.json"
+
+actual=$($mb diff --since-last-success --markers "$markers" --scope stack1 --dependencies)
+expected="app1: libs/lib1, libs/lib2
+app2: libs/lib2, libs/lib3
+app3: app4/lib, libs/lib3
+app4/lib: 
+libs/lib1: libs/lib3
+libs/lib2: libs/lib3
+libs/lib3: 
+stack1: app1, app2, app3"
+
+assert_eq "monobuild diff --since-last-success without markers" "$actual" "$expected"
+
+$mb mark --markers "$markers" app1 unknown 2>/dev/null
+
+actual=$($mb diff --since-last-success --markers "$markers" --scope app1)
+expected="app1: 
+libs/lib1: 
+libs/lib2: 
+libs/lib3: "
+
+assert_eq "monobuild mark with an unknown component marks nothing" "$actual" "$expected"
+
+$mb mark --markers "$markers" app1 app2 app3 app4 app4/lib libs/lib1 libs/lib3 stack1
+
+actual=$($mb diff --since-last-success --markers "$markers")
+expected="libs/lib2: "
+
+assert_eq "monobuild diff --since-last-success after mark" "$actual" "$expected"
+
+rm "$markers"
+
+# monobuild hash
+printf "\nHash command:\n"
+
+actual=$($mb hash app1 libs/lib1 | cut -d " " -f 1)
+expected="app1:
+libs/lib1:"
+
+assert_eq "monobuild hash" "$actual" "$expected"
+
+actual=$($mb hash --revision HEAD app1)
+expected=$($mb hash app1)
+
+assert_eq "monobuild hash --revision" "$actual" "$expected"
+
+actual=$($mb hash --ignore 'Dependencies' app1)
+unexpected=$($mb hash app1)
+
+assert_eq "monobuild hash --ignore" "$([ "$actual" != "$unexpected" ] && echo changed)" "changed"
+
+actual=$($mb hash --chain weak stack1)
+unexpected=$($mb hash --chain strong stack1)
+
+assert_eq "monobuild hash --chain" "$([ "$actual" != "$unexpected" ] && echo changed)" "changed"
+
+# monobuild plan
+printf "\nPlan command:\n"
+
+changes="libs/lib2/change.txt
+app4/lib/lib.bin"
+timings="app1: 2m
+app2: 1m
+app3: 1m
+stack1: 30s"
+echo "$timings" > timings.txt
+$mb timing --timings timings.txt app2 5m
+
+actual=$(echo "$changes" | $mb plan --timings timings.txt --workers 2 -)
+expected="critical path: app2 (5m0s) -> stack1 (30s)
+critical path duration: 5m30s
+total duration: 8m30s
+workers: 2
+estimated wall-clock time: 5m30s"
+
+assert_eq "monobuild plan --workers 2" "$actual" "$expected"
+
+rm timings.txt
+
+# monobuild stats
+printf "\nStats command:\n"
+
+actual=$($mb stats --scope 'libs/*')
+expected="COMPONENT  WEAK IN  WEAK OUT  STRONG IN  STRONG OUT  DEPENDENTS  DEPTH  BETWEENNESS
+libs/lib1  1        1         0          0           2           0      0.50
+libs/lib2  2        1         0          0           3           0      0.50
+libs/lib3  4        0         0          0           6           0      0.00
+
+components: 3
+weak dependencies: 2
+strong dependencies: 0
+max depth: 0
+mean dependents: 3.67
+most depended on: libs/lib3
+most central: libs/lib1"
+
+assert_eq "monobuild stats --scope 'libs/*'" "$actual" "$expected"
+
+# monobuild check
+printf "\nCheck command:\n"
+
+rules="# layering
+deny libs/** -> app*
+deny ** -> !app2
+only app1 -> libs/lib1"
+echo "$rules" > rules.txt
+
+actual=$($mb check --rules rules.txt)
+status=$?
+expected="app1/Dependencies:4: app1: dependency on 'libs/lib2' breaks rule 'only app1 -> libs/lib1' (rules.txt:4)
+stack1/Dependencies:5: stack1: strong dependency on 'app2' breaks rule 'deny ** -> !app2' (rules.txt:3)"
+
+assert_eq "monobuild check" "$actual" "$expected"
+assert_eq "monobuild check exit status" "$status" "1"
+
+rm rules.txt
+
+# monobuild tags
+printf "\nTags:\n"
+cd ../component-manifests
+
+# monobuild print --tag service
+actual=$($mb print --tag service)
+expected="app1: 
+stack1: app1"
+
+assert_eq "monobuild print --tag service" "$actual" "$expected"
+
+# monobuild print --tag service+team:payments --tag library
+actual=$($mb print --tag service+team:payments --tag library)
+expected="libs/lib1: 
+stack1: "
+
+assert_eq "monobuild print --tag service+team:payments --tag library" "$actual" "$expected"
+
+# monobuild print --exclude-tag service
+actual=$($mb print --exclude-tag service)
+expected="libs/lib1: "
+
+assert_eq "monobuild print --exclude-tag service" "$actual" "$expected"
+
+# monobuild diff --tag service
+actual=$(echo "libs/lib1/lib.go" | $mb diff --dependencies --tag service -)
+expected="app1: 
+stack1: app1"
+
+assert_eq "monobuild diff --dependencies --tag service" "$actual" "$expected"
+
+# monobuild diff --format gitlab-ci
+actual=$(echo "libs/lib1/lib.go" | $mb diff --format gitlab-ci --scope stack1 -)
+expected="stages:
+  - build-1
+  - build-2
+build:app1:
+  stage: build-1
+  image: golang:1.17
+  tags:
+    - docker
+  needs: []
+  variables:
+    BUILD_COMMAND: make build
+    COMPONENT: app1
+  script:
+    - cd app1
+    - make build
+build:libs/lib1:
+  stage: build-1
+  image: golang:1.17
+  tags:
+    - docker
+  needs: []
+  variables:
+    BUILD_COMMAND: go build ./...
+    COMPONENT: libs/lib1
+  script:
+    - cd libs/lib1
+    - go build ./...
+build:stack1:
+  stage: build-2
+  image: golang:1.17
+  tags:
+    - docker
+  needs:
+    - build:app1
+  variables:
+    BUILD_COMMAND: make build
+    COMPONENT: stack1
+  timeout: 10 minutes
+  script:
+    - cd stack1
+    - make build"
+
+assert_eq "monobuild diff --format gitlab-ci" "$actual" "$expected"
+
+cd ../manifests-test
+
 # Return a status based on success
 exit $exit_status
 
@@ -0,0 +1,12 @@
+package main
+
+import (
+	"fmt"
+
+	"example.com/mono/libs/lib1"
+	"example.com/mono/libs/lib3/sub"
+)
+
+func main() {
+	fmt.Println(lib1.Name(), sub.Name())
+}
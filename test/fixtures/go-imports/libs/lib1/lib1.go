@@ -0,0 +1,6 @@
+package lib1
+
+// Name of the library
+func Name() string {
+	return "lib1"
+}
@@ -0,0 +1,6 @@
+package lib2
+
+// Name of the library
+func Name() string {
+	return "lib2"
+}
@@ -0,0 +1,8 @@
+package sub
+
+import "example.com/mono/libs/lib2"
+
+// Name of the library
+func Name() string {
+	return "lib3/sub uses " + lib2.Name()
+}